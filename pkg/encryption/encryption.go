@@ -0,0 +1,193 @@
+// Package encryption encrypts serialized telemetry reports to the collector's
+// public key, so that intermediaries, like TLS inspecting proxies, only ever
+// see ciphertext.
+//
+// Payloads are encrypted with HPKE (RFC 9180) in base mode using the
+// DHKEM(X25519, HKDF-SHA256), HKDF-SHA256 and ChaCha20Poly1305 suite.
+// An encrypted payload has the following format:
+//
+//	"kte1" | key ID length (1 byte) | key ID | encapsulated key | ciphertext
+//
+// The key ID identifies the collector's key pair, which allows to rotate it,
+// and it's bound to the ciphertext through HPKE's info parameter.
+package encryption
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/hpke"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// magic identifies encrypted payloads and the version of the format.
+var magic = []byte("kte1")
+
+// infoPrefix is the prefix of HPKE's info parameter, followed by the key ID.
+const infoPrefix = "kubernetes-telemetry report encryption v1 key "
+
+// ErrNotEncrypted is returned when decrypting a payload which is not encrypted.
+var ErrNotEncrypted = errors.New("payload is not encrypted")
+
+// ErrUnknownKey is returned when a payload is encrypted to a key which is not
+// known to the decrypter.
+type ErrUnknownKey struct {
+	KeyID string
+}
+
+func (e ErrUnknownKey) Error() string {
+	return fmt.Sprintf("payload is encrypted to unknown key %q", e.KeyID)
+}
+
+func suite() (hpke.KDF, hpke.AEAD) {
+	return hpke.HKDFSHA256(), hpke.ChaCha20Poly1305()
+}
+
+// GenerateKey generates a new X25519 key pair for the collector.
+func GenerateKey() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// IsEncrypted tells whether the payload is encrypted.
+func IsEncrypted(payload []byte) bool {
+	return bytes.HasPrefix(payload, magic)
+}
+
+// Encrypter encrypts payloads to a public key.
+type Encrypter struct {
+	keyID string
+	key   hpke.PublicKey
+}
+
+// NewEncrypter creates an encrypter which encrypts payloads to the provided
+// X25519 public key identified by the key ID.
+func NewEncrypter(keyID string, publicKey *ecdh.PublicKey) (*Encrypter, error) {
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	if publicKey == nil || publicKey.Curve() != ecdh.X25519() {
+		return nil, errors.New("public key has to be an X25519 key")
+	}
+	key, err := hpke.NewDHKEMPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return &Encrypter{
+		keyID: keyID,
+		key:   key,
+	}, nil
+}
+
+// Encrypt encrypts the payload.
+func (e *Encrypter) Encrypt(payload []byte) ([]byte, error) {
+	kdf, aead := suite()
+	ct, err := hpke.Seal(e.key, kdf, aead, info(e.keyID), payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt payload: %w", err)
+	}
+
+	out := make([]byte, 0, len(magic)+1+len(e.keyID)+len(ct))
+	out = append(out, magic...)
+	out = append(out, byte(len(e.keyID)))
+	out = append(out, e.keyID...)
+	return append(out, ct...), nil
+}
+
+// DecryptionKey is the collector's private key identified by the key ID.
+type DecryptionKey struct {
+	ID         string
+	PrivateKey *ecdh.PrivateKey
+}
+
+// Decrypter decrypts payloads. Keys can be added and removed while the
+// decrypter is in use to support key rotation. It's safe for concurrent use.
+type Decrypter struct {
+	lock sync.RWMutex
+	keys map[string]hpke.PrivateKey
+}
+
+// NewDecrypter creates a decrypter which decrypts payloads encrypted to any of
+// the provided keys.
+func NewDecrypter(keys ...DecryptionKey) (*Decrypter, error) {
+	d := &Decrypter{
+		keys: make(map[string]hpke.PrivateKey, len(keys)),
+	}
+	for _, k := range keys {
+		if err := d.AddKey(k); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// AddKey adds a key, replacing the key with the same ID if any.
+func (d *Decrypter) AddKey(k DecryptionKey) error {
+	if err := validateKeyID(k.ID); err != nil {
+		return err
+	}
+	if k.PrivateKey == nil || k.PrivateKey.Curve() != ecdh.X25519() {
+		return fmt.Errorf("private key %q has to be an X25519 key", k.ID)
+	}
+	key, err := hpke.NewDHKEMPrivateKey(k.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("invalid private key %q: %w", k.ID, err)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.keys[k.ID] = key
+	return nil
+}
+
+// RemoveKey removes the key with the ID.
+func (d *Decrypter) RemoveKey(id string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.keys, id)
+}
+
+// Decrypt decrypts the payload and returns the plaintext along with the ID of
+// the key it was encrypted to. It returns ErrNotEncrypted for payloads which
+// are not encrypted and ErrUnknownKey for payloads encrypted to unknown keys.
+func (d *Decrypter) Decrypt(payload []byte) ([]byte, string, error) {
+	if !IsEncrypted(payload) {
+		return nil, "", ErrNotEncrypted
+	}
+	rest := payload[len(magic):]
+	if len(rest) == 0 || len(rest) < 1+int(rest[0]) {
+		return nil, "", errors.New("malformed encrypted payload")
+	}
+	keyID := string(rest[1 : 1+int(rest[0])])
+	ct := rest[1+int(rest[0]):]
+
+	d.lock.RLock()
+	key, ok := d.keys[keyID]
+	d.lock.RUnlock()
+	if !ok {
+		return nil, "", ErrUnknownKey{KeyID: keyID}
+	}
+
+	kdf, aead := suite()
+	plaintext, err := hpke.Open(key, kdf, aead, info(keyID), ct)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	return plaintext, keyID, nil
+}
+
+func info(keyID string) []byte {
+	return []byte(infoPrefix + keyID)
+}
+
+func validateKeyID(id string) error {
+	if id == "" {
+		return errors.New("key ID cannot be empty")
+	}
+	if len(id) > math.MaxUint8 {
+		return fmt.Errorf("key ID cannot be longer than %d bytes", math.MaxUint8)
+	}
+	return nil
+}
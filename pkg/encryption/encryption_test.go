@@ -0,0 +1,74 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	e, err := NewEncrypter("collector-1", key.PublicKey())
+	require.NoError(t, err)
+	d, err := NewDecrypter(DecryptionKey{ID: "collector-1", PrivateKey: key})
+	require.NoError(t, err)
+
+	payload := []byte("<14>signal=test;key=value;\n")
+	ct, err := e.Encrypt(payload)
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(ct))
+	assert.False(t, bytes.Contains(ct, []byte("signal=test")), "payload should not be readable")
+
+	again, err := e.Encrypt(payload)
+	require.NoError(t, err)
+	assert.NotEqual(t, ct, again, "every encryption should use a fresh ephemeral key")
+
+	pt, keyID, err := d.Decrypt(ct)
+	require.NoError(t, err)
+	assert.Equal(t, payload, pt)
+	assert.Equal(t, "collector-1", keyID)
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		tampered := bytes.Clone(ct)
+		tampered[len(tampered)-1] ^= 0xff
+		_, _, err := d.Decrypt(tampered)
+		require.Error(t, err)
+	})
+
+	t.Run("key ID is bound to the ciphertext", func(t *testing.T) {
+		other, err := NewDecrypter(DecryptionKey{ID: "collector-2", PrivateKey: key})
+		require.NoError(t, err)
+		swapped := bytes.Replace(ct, []byte("collector-1"), []byte("collector-2"), 1)
+		_, _, err = other.Decrypt(swapped)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		d.RemoveKey("collector-1")
+		t.Cleanup(func() { require.NoError(t, d.AddKey(DecryptionKey{ID: "collector-1", PrivateKey: key})) })
+		_, _, err := d.Decrypt(ct)
+		var unknown ErrUnknownKey
+		require.ErrorAs(t, err, &unknown)
+		assert.Equal(t, "collector-1", unknown.KeyID)
+	})
+
+	t.Run("not encrypted", func(t *testing.T) {
+		_, _, err := d.Decrypt(payload)
+		require.ErrorIs(t, err, ErrNotEncrypted)
+		_, _, err = d.Decrypt([]byte("kte1"))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := NewEncrypter("", key.PublicKey())
+		require.Error(t, err)
+		_, err = NewEncrypter("id", nil)
+		require.Error(t, err)
+		_, err = NewDecrypter(DecryptionKey{ID: "id"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,284 @@
+package forwarders
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	defaultBatchMaxCount = 100
+	defaultBatchMaxBytes = 1 << 20 // 1 MiB
+	defaultBatchMaxDelay = 10 * time.Second
+
+	// batchLengthPrefixSize is the size of the big endian uint32 length prefix.
+	batchLengthPrefixSize = 4
+)
+
+// BatchFraming defines how payloads are delimited in a batch.
+type BatchFraming int
+
+const (
+	// BatchFramingNewline separates payloads with a newline, as in newline
+	// delimited JSON. Payloads must not contain newlines, except for a trailing
+	// one, which is not duplicated: the batch forwarder rejects them. Use
+	// BatchFramingLengthPrefixed for payloads like pretty printed JSON.
+	BatchFramingNewline BatchFraming = iota
+	// BatchFramingLengthPrefixed prefixes every payload with its length as
+	// a 4 byte big endian unsigned integer.
+	BatchFramingLengthPrefixed
+)
+
+// ErrForwarderClosed is returned when forwarding with a closed forwarder.
+var ErrForwarderClosed = errors.New("forwarder is closed")
+
+// SplitBatch splits a batch created by the batch forwarder into payloads.
+func SplitBatch(batch []byte, framing BatchFraming) ([][]byte, error) {
+	var payloads [][]byte
+	switch framing {
+	case BatchFramingNewline:
+		for line := range bytes.Lines(batch) {
+			payloads = append(payloads, bytes.TrimSuffix(line, []byte("\n")))
+		}
+	case BatchFramingLengthPrefixed:
+		for len(batch) > 0 {
+			if len(batch) < batchLengthPrefixSize {
+				return nil, errors.New("truncated length prefix")
+			}
+			n := binary.BigEndian.Uint32(batch)
+			batch = batch[batchLengthPrefixSize:]
+			if uint64(len(batch)) < uint64(n) {
+				return nil, fmt.Errorf("truncated payload: expected %d bytes, got %d", n, len(batch))
+			}
+			payloads = append(payloads, batch[:n])
+			batch = batch[n:]
+		}
+	default:
+		return nil, fmt.Errorf("unknown batch framing %d", framing)
+	}
+	return payloads, nil
+}
+
+type batchForwarder struct {
+	logger    logr.Logger
+	forwarder forwarder
+
+	maxCount int
+	maxBytes int
+	maxDelay time.Duration
+	framing  BatchFraming
+
+	// lock guards the batch and is held while the batch is sent, so that
+	// batches are sent in order.
+	lock   sync.Mutex
+	batch  bytes.Buffer
+	count  int
+	timer  *time.Timer
+	gen    uint64
+	closed bool
+}
+
+// OptBatch is the option function type that can configure the batch forwarder.
+type OptBatch func(*batchForwarder)
+
+// OptBatchMaxCount returns an option that sets the number of payloads which
+// triggers sending the batch, 100 by default.
+func OptBatchMaxCount(count int) OptBatch {
+	return func(bf *batchForwarder) {
+		bf.maxCount = count
+	}
+}
+
+// OptBatchMaxBytes returns an option that sets the maximum size of a batch in
+// bytes, including framing, 1 MiB by default. A payload which doesn't fit in
+// the current batch is sent in the next one, and a payload which is larger than
+// the maximum size is sent in a batch of its own.
+func OptBatchMaxBytes(size int) OptBatch {
+	return func(bf *batchForwarder) {
+		bf.maxBytes = size
+	}
+}
+
+// OptBatchMaxDelay returns an option that sets how long payloads can wait in
+// the batch before it's sent, 10s by default.
+func OptBatchMaxDelay(delay time.Duration) OptBatch {
+	return func(bf *batchForwarder) {
+		bf.maxDelay = delay
+	}
+}
+
+// OptBatchFraming returns an option that sets how payloads are delimited in
+// a batch, BatchFramingNewline by default.
+func OptBatchFraming(framing BatchFraming) OptBatch {
+	return func(bf *batchForwarder) {
+		bf.framing = framing
+	}
+}
+
+// NewBatchForwarder creates a forwarder which gathers received payloads and
+// sends them as a single batch with the provided forwarder, when the batch
+// reaches the maximum count or size, or when its oldest payload has waited for
+// the maximum delay. Receivers can use SplitBatch to get the payloads back.
+//
+// A batch which fails to be sent is dropped. The error is returned by Forward
+// when the batch includes the forwarded payload, and by Flush and Close, and
+// it's logged otherwise. Wrap the provided forwarder in a retry or a queue
+// forwarder to retry failed batches.
+//
+// Close sends the pending batch and closes the provided forwarder if it
+// implements io.Closer. Consumers created with telemetry.OptConsumerCloseForwarder
+// call it when they are closed.
+func NewBatchForwarder(f forwarder, logger logr.Logger, opts ...OptBatch) (*batchForwarder, error) {
+	if f == nil {
+		return nil, errors.New("forwarder cannot be nil")
+	}
+
+	bf := &batchForwarder{
+		logger:    logger,
+		forwarder: f,
+		maxCount:  defaultBatchMaxCount,
+		maxBytes:  defaultBatchMaxBytes,
+		maxDelay:  defaultBatchMaxDelay,
+		framing:   BatchFramingNewline,
+	}
+	for _, opt := range opts {
+		opt(bf)
+	}
+	switch {
+	case bf.maxCount < 1:
+		return nil, errors.New("batch maximum count has to be at least 1")
+	case bf.maxBytes < 1:
+		return nil, errors.New("batch maximum size has to be positive")
+	case bf.maxDelay <= 0:
+		return nil, errors.New("batch maximum delay has to be positive")
+	case bf.framing != BatchFramingNewline && bf.framing != BatchFramingLengthPrefixed:
+		return nil, fmt.Errorf("unknown batch framing %d", bf.framing)
+	}
+	return bf, nil
+}
+
+// Name returns the name of the forwarder.
+func (bf *batchForwarder) Name() string {
+	return "BatchForwarder(" + bf.forwarder.Name() + ")"
+}
+
+// Forward adds the payload to the batch and sends the batch when it's full. It
+// only returns an error when the payload wasn't added or the batch it was added
+// to failed to be sent.
+func (bf *batchForwarder) Forward(ctx context.Context, payload []byte) error {
+	switch bf.framing {
+	case BatchFramingLengthPrefixed:
+		if uint64(len(payload)) > math.MaxUint32 {
+			return fmt.Errorf("payload of %d bytes is too large to be length prefixed", len(payload))
+		}
+	case BatchFramingNewline:
+		if bytes.Contains(bytes.TrimSuffix(payload, []byte("\n")), []byte("\n")) {
+			return errors.New("payload contains newlines, it can't be newline delimited")
+		}
+	}
+
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	if bf.closed {
+		return ErrForwarderClosed
+	}
+
+	framed := bf.frame(payload)
+	if bf.count > 0 && bf.batch.Len()+len(framed) > bf.maxBytes {
+		// The payload doesn't make it into the pending batch, so failing to
+		// send that batch isn't an error of this payload.
+		if err := bf.flushLocked(ctx); err != nil {
+			bf.logger.Error(err, "failed to forward batch, dropping it", "forwarder", bf.forwarder.Name())
+		}
+	}
+
+	bf.batch.Write(framed)
+	bf.count++
+	if bf.count >= bf.maxCount || bf.batch.Len() >= bf.maxBytes {
+		return bf.flushLocked(ctx)
+	}
+	if bf.count == 1 {
+		gen := bf.gen
+		bf.timer = time.AfterFunc(bf.maxDelay, func() { bf.flushAfterDelay(gen) })
+	}
+	return nil
+}
+
+// Flush sends the pending batch, if any.
+func (bf *batchForwarder) Flush(ctx context.Context) error {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	return bf.flushLocked(ctx)
+}
+
+// Close sends the pending batch, closes the forwarder and the wrapped forwarder
+// if it implements io.Closer. Payloads forwarded afterwards are rejected with
+// ErrForwarderClosed.
+func (bf *batchForwarder) Close() error {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	if bf.closed {
+		return nil
+	}
+	bf.closed = true
+
+	// The context of the caller is usually done at shutdown, so the pending
+	// batch gets a context of its own.
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDeadline)
+	defer cancel()
+	return errors.Join(bf.flushLocked(ctx), closeForwarders(bf.forwarder))
+}
+
+func (bf *batchForwarder) flushAfterDelay(gen uint64) {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+
+	// The batch the timer was started for has already been sent.
+	if gen != bf.gen {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDeadline)
+	defer cancel()
+	if err := bf.flushLocked(ctx); err != nil {
+		bf.logger.Error(err, "failed to forward batch, dropping it", "forwarder", bf.forwarder.Name())
+	}
+}
+
+// flushLocked sends the pending batch. It has to be called with the lock held.
+func (bf *batchForwarder) flushLocked(ctx context.Context) error {
+	if bf.count == 0 {
+		return nil
+	}
+	if bf.timer != nil {
+		bf.timer.Stop()
+		bf.timer = nil
+	}
+	bf.gen++
+
+	batch := bytes.Clone(bf.batch.Bytes())
+	bf.batch.Reset()
+	bf.count = 0
+	return bf.forwarder.Forward(ctx, batch)
+}
+
+func (bf *batchForwarder) frame(payload []byte) []byte {
+	if bf.framing == BatchFramingLengthPrefixed {
+		framed := make([]byte, 0, batchLengthPrefixSize+len(payload))
+		framed = binary.BigEndian.AppendUint32(framed, uint32(len(payload))) //nolint:gosec
+		return append(framed, payload...)
+	}
+	if bytes.HasSuffix(payload, []byte("\n")) {
+		return payload
+	}
+	return append(bytes.Clone(payload), '\n')
+}
@@ -0,0 +1,149 @@
+package forwarders
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchForwarder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("flushes when the batch reaches the maximum count", func(t *testing.T) {
+		f := &unreliableForwarder{}
+		bf, err := NewBatchForwarder(f, logr.Discard(), OptBatchMaxCount(3))
+		require.NoError(t, err)
+		assert.Equal(t, "BatchForwarder(unreliableForwarder)", bf.Name())
+
+		for i := range 4 {
+			require.NoError(t, bf.Forward(ctx, fmt.Appendf(nil, `{"report":%d}`, i)))
+		}
+		assert.Equal(t, []string{"{\"report\":0}\n{\"report\":1}\n{\"report\":2}\n"}, f.received())
+
+		require.NoError(t, bf.Close())
+		assert.Equal(t, "{\"report\":3}\n", f.received()[1])
+		require.ErrorIs(t, bf.Forward(ctx, []byte("late")), ErrForwarderClosed)
+	})
+
+	t.Run("flushes when the batch reaches the maximum size", func(t *testing.T) {
+		f := &unreliableForwarder{}
+		bf, err := NewBatchForwarder(f, logr.Discard(),
+			OptBatchMaxBytes(22),
+			OptBatchFraming(BatchFramingLengthPrefixed),
+		)
+		require.NoError(t, err)
+
+		// Every framed payload takes 4 + 7 bytes, so 2 fit in a batch.
+		for i := range 5 {
+			require.NoError(t, bf.Forward(ctx, fmt.Appendf(nil, "report%d", i)))
+		}
+		require.Len(t, f.received(), 2)
+		require.NoError(t, bf.Flush(ctx))
+
+		var payloads []string
+		for _, batch := range f.received() {
+			split, err := SplitBatch([]byte(batch), BatchFramingLengthPrefixed)
+			require.NoError(t, err)
+			for _, p := range split {
+				payloads = append(payloads, string(p))
+			}
+		}
+		assert.Equal(t, []string{"report0", "report1", "report2", "report3", "report4"}, payloads)
+		assert.Len(t, f.received(), 3)
+
+		t.Log("payload larger than the maximum size is sent alone")
+		require.NoError(t, bf.Forward(ctx, []byte("a very long report")))
+		assert.Len(t, f.received(), 4)
+	})
+
+	t.Run("flushes after the maximum delay", func(t *testing.T) {
+		f := &unreliableForwarder{}
+		bf, err := NewBatchForwarder(f, logr.Discard(), OptBatchMaxDelay(50*time.Millisecond))
+		require.NoError(t, err)
+		defer func() { require.NoError(t, bf.Close()) }()
+
+		require.NoError(t, bf.Forward(ctx, []byte("report-0\n")))
+		require.NoError(t, bf.Forward(ctx, []byte("report-1")))
+		assert.Empty(t, f.received())
+		require.Eventually(t, func() bool { return len(f.received()) == 1 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, "report-0\nreport-1\n", f.received()[0])
+
+		split, err := SplitBatch([]byte(f.received()[0]), BatchFramingNewline)
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("report-0"), []byte("report-1")}, split)
+	})
+
+	t.Run("returns errors of the wrapped forwarder", func(t *testing.T) {
+		f := &unreliableForwarder{down: true, err: ErrHTTPStatus{StatusCode: 503}}
+		bf, err := NewBatchForwarder(f, logr.Discard(), OptBatchMaxCount(1))
+		require.NoError(t, err)
+
+		var statusErr ErrHTTPStatus
+		require.ErrorAs(t, bf.Forward(ctx, []byte("report")), &statusErr)
+	})
+
+	t.Run("payloads are queued when sending the previous batch fails", func(t *testing.T) {
+		f := &unreliableForwarder{down: true, err: ErrHTTPStatus{StatusCode: 503}}
+		bf, err := NewBatchForwarder(f, logr.Discard(), OptBatchMaxBytes(16))
+		require.NoError(t, err)
+
+		require.NoError(t, bf.Forward(ctx, []byte("report-0")))
+		require.NoError(t, bf.Forward(ctx, []byte("report-1")), "the failed batch doesn't include report-1")
+
+		f.setDown(false)
+		require.NoError(t, bf.Close())
+		assert.Equal(t, []string{"report-1\n"}, f.received())
+	})
+
+	t.Run("close closes the wrapped forwarder", func(t *testing.T) {
+		f := &closingForwarder{}
+		bf, err := NewBatchForwarder(f, logr.Discard())
+		require.NoError(t, err)
+
+		require.NoError(t, bf.Forward(ctx, []byte("report")))
+		require.NoError(t, bf.Close())
+		assert.Equal(t, []string{"report\n"}, f.received())
+		assert.Equal(t, 1, f.closed)
+		require.NoError(t, bf.Close())
+		assert.Equal(t, 1, f.closed)
+	})
+
+	t.Run("payloads with newlines are rejected with newline framing", func(t *testing.T) {
+		f := &unreliableForwarder{}
+		bf, err := NewBatchForwarder(f, logr.Discard())
+		require.NoError(t, err)
+
+		require.Error(t, bf.Forward(ctx, []byte("{\n  \"report\": 0\n}\n")))
+		require.NoError(t, bf.Forward(ctx, []byte("{\"report\":1}\n")))
+		require.NoError(t, bf.Close())
+		assert.Equal(t, []string{"{\"report\":1}\n"}, f.received())
+
+		bf, err = NewBatchForwarder(f, logr.Discard(), OptBatchFraming(BatchFramingLengthPrefixed))
+		require.NoError(t, err)
+		require.NoError(t, bf.Forward(ctx, []byte("{\n  \"report\": 0\n}\n")))
+		require.NoError(t, bf.Close())
+	})
+
+	t.Run("invalid batches and options", func(t *testing.T) {
+		_, err := SplitBatch([]byte{0, 0, 0, 5, 'a'}, BatchFramingLengthPrefixed)
+		require.Error(t, err)
+		_, err = SplitBatch([]byte{0, 0}, BatchFramingLengthPrefixed)
+		require.Error(t, err)
+
+		f := &unreliableForwarder{}
+		for _, opt := range []OptBatch{
+			OptBatchMaxCount(0),
+			OptBatchMaxBytes(0),
+			OptBatchMaxDelay(0),
+			OptBatchFraming(BatchFraming(5)),
+		} {
+			_, err := NewBatchForwarder(f, logr.Discard(), opt)
+			require.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package forwarders
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const defaultCertificateReloadInterval = 30 * time.Second
+
+// certificateBundle holds PEM encoded client certificate, its key and CA bundle.
+type certificateBundle struct {
+	cert []byte
+	key  []byte
+	ca   []byte
+}
+
+type certificateReloader struct {
+	load     func(context.Context) (certificateBundle, error)
+	interval time.Duration
+	now      func() time.Time
+
+	// lock guards the fields below.
+	lock        sync.Mutex
+	lastCheck   time.Time
+	fingerprint [sha256.Size]byte
+	cert        *tls.Certificate
+	roots       *x509.CertPool
+}
+
+// OptCertificateReloader is the option function type that can configure the
+// certificate reloader.
+type OptCertificateReloader func(*certificateReloader)
+
+// OptCertificateReloadInterval returns an option that sets how often the
+// certificates are checked for changes, 30s by default. Certificates are
+// checked when connecting, so they are not checked more often than connections
+// are made.
+func OptCertificateReloadInterval(interval time.Duration) OptCertificateReloader {
+	return func(r *certificateReloader) {
+		r.interval = interval
+	}
+}
+
+// NewFileCertificateReloader creates a certificate reloader which loads the PEM
+// encoded client certificate and key, and the CA bundle used to verify the
+// server, from files. Either the certificate and key files, or the CA file can
+// be empty, to only use the other. Changes to the files are picked up without
+// restarting.
+func NewFileCertificateReloader(certFile, keyFile, caFile string, opts ...OptCertificateReloader) (*certificateReloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("certificate and key files have to be provided together")
+	}
+	if certFile == "" && caFile == "" {
+		return nil, errors.New("certificate and key files or CA file have to be provided")
+	}
+
+	readFile := func(name string) ([]byte, error) {
+		if name == "" {
+			return nil, nil
+		}
+		return os.ReadFile(name)
+	}
+	return newCertificateReloader(func(context.Context) (certificateBundle, error) {
+		var (
+			b   certificateBundle
+			err error
+		)
+		if b.cert, err = readFile(certFile); err != nil {
+			return b, err
+		}
+		if b.key, err = readFile(keyFile); err != nil {
+			return b, err
+		}
+		b.ca, err = readFile(caFile)
+		return b, err
+	}, opts)
+}
+
+// NewSecretCertificateReloader creates a certificate reloader which loads the
+// client certificate and key, and the CA bundle used to verify the server, from
+// the tls.crt, tls.key and ca.crt keys of a Kubernetes Secret, like the ones
+// managed by cert-manager. Either the certificate and key, or the CA bundle can
+// be missing, to only use the other. Changes to the Secret are picked up without
+// restarting.
+func NewSecretCertificateReloader(
+	kc kubernetes.Interface, namespace, name string, opts ...OptCertificateReloader,
+) (*certificateReloader, error) {
+	if kc == nil {
+		return nil, errors.New("kubernetes client cannot be nil")
+	}
+	return newCertificateReloader(func(ctx context.Context) (certificateBundle, error) {
+		secret, err := kc.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return certificateBundle{}, err
+		}
+		return certificateBundle{
+			cert: secret.Data[corev1.TLSCertKey],
+			key:  secret.Data[corev1.TLSPrivateKeyKey],
+			ca:   secret.Data[corev1.ServiceAccountRootCAKey],
+		}, nil
+	}, opts)
+}
+
+func newCertificateReloader(
+	load func(context.Context) (certificateBundle, error), opts []OptCertificateReloader,
+) (*certificateReloader, error) {
+	r := &certificateReloader{
+		load:     load,
+		interval: defaultCertificateReloadInterval,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.interval <= 0 {
+		return nil, errors.New("certificate reload interval has to be positive")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	if _, _, err := r.current(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSOpt returns a TLSOpt which makes connections use the reloaded client
+// certificate and, when a CA bundle was loaded, verify the server against the
+// reloaded CA bundle instead of RootCAs. The option can be used with
+// NewTLSForwarder or OptTLSForwarderTLSConfig, which apply it for every
+// connection, so that every connection uses the current CA bundle. Once a CA
+// bundle was loaded, reloads without one are ignored, so that the server isn't
+// verified against RootCAs again.
+func (r *certificateReloader) TLSOpt() TLSOpt {
+	return func(c *tls.Config) {
+		c.GetClientCertificate = r.getClientCertificate
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+		defer cancel()
+		if _, roots, err := r.current(ctx); err == nil && roots != nil {
+			c.RootCAs = roots
+		}
+	}
+}
+
+func (r *certificateReloader) getClientCertificate(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, _, err := r.current(cri.Context())
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil {
+		// No certificate is sent.
+		return &tls.Certificate{}, nil
+	}
+	return cert, nil
+}
+
+// current returns the current certificate and root pool, reloading them when
+// the reload interval has passed. When reloading fails, the previously loaded
+// ones are kept.
+func (r *certificateReloader) current(ctx context.Context) (*tls.Certificate, *x509.CertPool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	loaded := !r.lastCheck.IsZero()
+	if loaded && r.now().Sub(r.lastCheck) < r.interval {
+		return r.cert, r.roots, nil
+	}
+
+	if err := r.reloadLocked(ctx); err != nil {
+		if !loaded {
+			return nil, nil, err
+		}
+		// Keep using the previous certificates and retry on the next connection.
+		return r.cert, r.roots, nil
+	}
+	return r.cert, r.roots, nil
+}
+
+// reloadLocked loads the certificates and parses them if they changed. It has
+// to be called with the lock held.
+func (r *certificateReloader) reloadLocked(ctx context.Context) error {
+	b, err := r.load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load certificates: %w", err)
+	}
+	fingerprint := sha256.Sum256(bytes.Join([][]byte{b.cert, b.key, b.ca}, []byte{0}))
+	if fingerprint == r.fingerprint {
+		r.lastCheck = r.now()
+		return nil
+	}
+
+	if (len(b.cert) == 0) != (len(b.key) == 0) {
+		return errors.New("certificate and key have to be provided together")
+	}
+	if len(b.cert) == 0 && len(b.ca) == 0 {
+		return errors.New("neither certificate nor CA bundle found")
+	}
+
+	var cert *tls.Certificate
+	if len(b.cert) > 0 {
+		c, err := tls.X509KeyPair(b.cert, b.key)
+		if err != nil {
+			return fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		cert = &c
+	}
+	if len(b.ca) == 0 && r.roots != nil {
+		// Falling back to RootCAs could make servers trusted which weren't.
+		return errors.New("CA bundle was removed")
+	}
+	var roots *x509.CertPool
+	if len(b.ca) > 0 {
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(b.ca) {
+			return errors.New("failed to parse CA bundle: no certificates found")
+		}
+	}
+
+	r.cert = cert
+	r.roots = roots
+	r.fingerprint = fingerprint
+	r.lastCheck = r.now()
+	return nil
+}
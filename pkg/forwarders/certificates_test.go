@@ -0,0 +1,302 @@
+package forwarders
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgo_fake "k8s.io/client-go/kubernetes/fake"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns a PEM encoded certificate and key signed by the CA.
+func (ca testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+// mTLSTestServer requires client certificates and reports the common names of
+// the clients. Its certificate can be replaced while it's running.
+type mTLSTestServer struct {
+	listener net.Listener
+	cert     atomic.Pointer[tls.Certificate]
+	clients  chan string
+}
+
+func newMTLSTestServer(t *testing.T, certPEM, keyPEM []byte) *mTLSTestServer {
+	t.Helper()
+	s := &mTLSTestServer{clients: make(chan string, 10)}
+	s.setCertificate(t, certPEM, keyPEM)
+
+	listener, err := tls.Listen("tcp4", "localhost:0", &tls.Config{
+		MinVersion: tls.VersionTLS13,
+		ClientAuth: tls.RequireAnyClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return s.cert.Load(), nil
+		},
+	})
+	require.NoError(t, err)
+	s.listener = listener
+
+	var conns sync.WaitGroup
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns.Go(func() {
+				defer conn.Close()
+				tlsConn := conn.(*tls.Conn)
+				if err := tlsConn.Handshake(); err != nil {
+					return
+				}
+				if peers := tlsConn.ConnectionState().PeerCertificates; len(peers) > 0 {
+					s.clients <- peers[0].Subject.CommonName
+				}
+				_, _ = io.Copy(io.Discard, conn)
+			})
+		}
+	}()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		conns.Wait()
+	})
+	return s
+}
+
+func (s *mTLSTestServer) setCertificate(t *testing.T, certPEM, keyPEM []byte) {
+	t.Helper()
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	s.cert.Store(&cert)
+}
+
+func (s *mTLSTestServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *mTLSTestServer) assertClient(t *testing.T, expected string) {
+	t.Helper()
+	select {
+	case name := <-s.clients:
+		assert.Equal(t, expected, name)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for client")
+	}
+}
+
+func TestFileCertificateReloader(t *testing.T) {
+	ca1, ca2 := newTestCA(t, "ca-1"), newTestCA(t, "ca-2")
+	serverCert, serverKey := ca1.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	srv := newMTLSTestServer(t, serverCert, serverKey)
+
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	writeFiles := func(cert, key, ca []byte) {
+		require.NoError(t, os.WriteFile(certFile, cert, 0o600))
+		require.NoError(t, os.WriteFile(keyFile, key, 0o600))
+		require.NoError(t, os.WriteFile(caFile, ca, 0o600))
+	}
+	clientCert, clientKey := ca1.issue(t, "client-1", x509.ExtKeyUsageClientAuth)
+	writeFiles(clientCert, clientKey, ca1.pem)
+
+	r, err := NewFileCertificateReloader(certFile, keyFile, caFile, OptCertificateReloadInterval(time.Minute))
+	require.NoError(t, err)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	tf, err := NewTLSForwarder(srv.Addr(), logr.Discard(), r.TLSOpt())
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client-1")
+
+	t.Log("rotated client certificate is picked up after the reload interval")
+	clientCert, clientKey = ca1.issue(t, "client-2", x509.ExtKeyUsageClientAuth)
+	writeFiles(clientCert, clientKey, ca1.pem)
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client-1")
+	now = now.Add(time.Minute)
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client-2")
+
+	t.Log("server certificate is verified against the reloaded CA bundle")
+	serverCert, serverKey = ca2.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	srv.setCertificate(t, serverCert, serverKey)
+	err = tf.Forward(ctx, []byte("report"))
+	var verificationErr *tls.CertificateVerificationError
+	require.ErrorAs(t, err, &verificationErr)
+	assert.False(t, DefaultRetryClassifier(err))
+
+	writeFiles(clientCert, clientKey, ca2.pem)
+	now = now.Add(time.Minute)
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client-2")
+
+	t.Log("invalid files don't replace the loaded certificates")
+	writeFiles([]byte("invalid"), clientKey, ca2.pem)
+	now = now.Add(time.Minute)
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client-2")
+}
+
+func TestFileCertificateReloaderCABundleChanges(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	srv := newMTLSTestServer(t, serverCert, serverKey)
+
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	require.NoError(t, os.WriteFile(certFile, clientCert, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, clientKey, 0o600))
+	require.NoError(t, os.WriteFile(caFile, nil, 0o600))
+
+	r, err := NewFileCertificateReloader(certFile, keyFile, caFile, OptCertificateReloadInterval(time.Minute))
+	require.NoError(t, err)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	tf, err := NewTLSForwarder(srv.Addr(), logr.Discard(), r.TLSOpt())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Log("without a CA bundle the server is verified against the system roots")
+	var verificationErr *tls.CertificateVerificationError
+	require.ErrorAs(t, tf.Forward(ctx, []byte("report")), &verificationErr)
+
+	t.Log("CA bundle added later is used")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+	now = now.Add(time.Minute)
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client")
+
+	t.Log("removed CA bundle keeps being used")
+	require.NoError(t, os.WriteFile(caFile, nil, 0o600))
+	now = now.Add(time.Minute)
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client")
+}
+
+func TestSecretCertificateReloader(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	srv := newMTLSTestServer(t, serverCert, serverKey)
+
+	clientCert, clientKey := ca.issue(t, "client-1", x509.ExtKeyUsageClientAuth)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kong", Name: "telemetry-client"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:              clientCert,
+			corev1.TLSPrivateKeyKey:        clientKey,
+			corev1.ServiceAccountRootCAKey: ca.pem,
+		},
+	}
+	kc := clientgo_fake.NewClientset(secret)
+
+	r, err := NewSecretCertificateReloader(kc, "kong", "telemetry-client", OptCertificateReloadInterval(time.Minute))
+	require.NoError(t, err)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	tf, err := NewTLSForwarder(srv.Addr(), logr.Discard(), r.TLSOpt())
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client-1")
+
+	t.Log("certificate renewed in the Secret is picked up")
+	clientCert, clientKey = ca.issue(t, "client-2", x509.ExtKeyUsageClientAuth)
+	secret.Data[corev1.TLSCertKey] = clientCert
+	secret.Data[corev1.TLSPrivateKeyKey] = clientKey
+	_, err = kc.CoreV1().Secrets("kong").Update(ctx, secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	now = now.Add(time.Minute)
+	require.NoError(t, tf.Forward(ctx, []byte("report")))
+	srv.assertClient(t, "client-2")
+
+	t.Run("missing Secret", func(t *testing.T) {
+		_, err := NewSecretCertificateReloader(kc, "kong", "missing")
+		require.Error(t, err)
+	})
+}
+
+func TestInvalidCertificateReloader(t *testing.T) {
+	_, err := NewFileCertificateReloader("", "", "")
+	require.Error(t, err)
+	_, err = NewFileCertificateReloader("tls.crt", "", "")
+	require.Error(t, err)
+	_, err = NewFileCertificateReloader("", "", filepath.Join(t.TempDir(), "missing.crt"))
+	require.Error(t, err)
+	_, err = NewSecretCertificateReloader(nil, "kong", "secret")
+	require.Error(t, err)
+}
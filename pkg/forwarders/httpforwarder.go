@@ -0,0 +1,261 @@
+package forwarders
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kong/kubernetes-telemetry/pkg/log"
+	"github.com/kong/kubernetes-telemetry/pkg/serializers"
+)
+
+const (
+	defaultHTTPContentType = "application/octet-stream"
+	// maxHTTPErrorBodySize limits how much of an error response is kept in ErrHTTPStatus.
+	maxHTTPErrorBodySize = 1024
+	// maxHTTPDrainSize limits how much of a response body is read and discarded
+	// so that the connection can be reused. Connections of responses with larger
+	// bodies are closed instead.
+	maxHTTPDrainSize = 64 * 1024
+)
+
+// ErrHTTPStatus is returned by the HTTP forwarder when the server responds with
+// a status code other than 2xx.
+type ErrHTTPStatus struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Body holds (the beginning of) the response body.
+	Body []byte
+	// RetryAfter is the delay requested by the server with the Retry-After
+	// header, or 0 when the header is absent.
+	RetryAfter time.Duration
+}
+
+func (e ErrHTTPStatus) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("reporting server responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("reporting server responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable tells whether the request might succeed when retried: true for
+// 408 Request Timeout, 429 Too Many Requests and 5xx responses, false otherwise.
+func (e ErrHTTPStatus) Retryable() bool {
+	return e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= http.StatusInternalServerError
+}
+
+type httpForwarder struct {
+	logger logr.Logger
+
+	url               string
+	method            string
+	contentType       string
+	headers           http.Header
+	client            *http.Client
+	tlsConf           *tls.Config
+	cloudEventsBinary bool
+}
+
+// OptHTTP is the option function type that can configure the HTTP forwarder.
+type OptHTTP func(*httpForwarder)
+
+// OptHTTPMethod returns an option that sets the HTTP method, POST by default.
+func OptHTTPMethod(method string) OptHTTP {
+	return func(f *httpForwarder) {
+		f.method = method
+	}
+}
+
+// OptHTTPHeader returns an option that sets a header sent with every request.
+func OptHTTPHeader(key, value string) OptHTTP {
+	return func(f *httpForwarder) {
+		f.headers.Set(key, value)
+	}
+}
+
+// OptHTTPContentType returns an option that sets the Content-Type header,
+// "application/octet-stream" by default.
+func OptHTTPContentType(contentType string) OptHTTP {
+	return func(f *httpForwarder) {
+		f.contentType = contentType
+	}
+}
+
+// OptHTTPBearerToken returns an option that authenticates requests with the
+// bearer token.
+func OptHTTPBearerToken(token string) OptHTTP {
+	return func(f *httpForwarder) {
+		f.headers.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// OptHTTPBasicAuth returns an option that authenticates requests with HTTP basic
+// authentication.
+func OptHTTPBasicAuth(username, password string) OptHTTP {
+	return func(f *httpForwarder) {
+		r := http.Request{Header: http.Header{}}
+		r.SetBasicAuth(username, password)
+		f.headers.Set("Authorization", r.Header.Get("Authorization"))
+	}
+}
+
+// OptHTTPTLSConfig returns an option that sets the TLS configuration used for
+// HTTPS URLs. It has no effect when a client is provided with OptHTTPClient.
+func OptHTTPTLSConfig(tlsConf *tls.Config) OptHTTP {
+	return func(f *httpForwarder) {
+		f.tlsConf = tlsConf
+	}
+}
+
+// OptHTTPClient returns an option that sets the HTTP client used to send requests.
+func OptHTTPClient(client *http.Client) OptHTTP {
+	return func(f *httpForwarder) {
+		f.client = client
+	}
+}
+
+// OptHTTPCloudEventsBinaryMode returns an option that sends payloads produced
+// by the CloudEvents serializer in binary mode: event attributes are sent as
+// "ce-" headers and the event's data as the body.
+func OptHTTPCloudEventsBinaryMode() OptHTTP {
+	return func(f *httpForwarder) {
+		f.cloudEventsBinary = true
+	}
+}
+
+// NewHTTPForwarder creates an HTTP forwarder which sends received serialized
+// reports in the body of HTTP requests to the provided URL.
+//
+// Proxies configured with HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables are honoured. Compressed payloads, as detected by
+// serializers.DetectCompression, are sent with the matching Content-Encoding.
+// Responses with a status code other than 2xx are reported as ErrHTTPStatus.
+func NewHTTPForwarder(rawURL string, logger logr.Logger, opts ...OptHTTP) (*httpForwarder, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	f := &httpForwarder{
+		logger:      logger,
+		url:         rawURL,
+		method:      http.MethodPost,
+		contentType: defaultHTTPContentType,
+		headers:     http.Header{},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if f.client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyFromEnvironment
+		if f.tlsConf != nil {
+			transport.TLSClientConfig = f.tlsConf.Clone()
+		}
+		f.client = &http.Client{
+			Transport: transport,
+			Timeout:   defaultTimeout,
+		}
+	}
+	return f, nil
+}
+
+// Name returns the name of the forwarder.
+func (hf *httpForwarder) Name() string {
+	return "HTTPForwarder"
+}
+
+// Forward sends the received payload to the configured URL.
+func (hf *httpForwarder) Forward(ctx context.Context, payload []byte) error {
+	header := hf.headers.Clone()
+	header.Set("Content-Type", hf.contentType)
+	if hf.cloudEventsBinary {
+		ceHeader, data, err := serializers.CloudEventToBinaryMode(payload)
+		if err != nil {
+			return fmt.Errorf("failed to convert event to binary mode: %w", err)
+		}
+		for k, v := range ceHeader {
+			header[k] = v
+		}
+		payload = data
+	}
+	if c := serializers.DetectCompression(payload); c != serializers.CompressionNone {
+		header.Set("Content-Encoding", c.ContentEncoding())
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultDeadline)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, hf.method, hf.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header = header
+
+	resp, err := hf.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to reporting server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPErrorBodySize))
+	if err != nil && !errors.Is(err, io.EOF) {
+		hf.logger.V(log.DebugLevel).Info("failed to read response body", "error", err.Error())
+	}
+	// Drain the rest of the body so that the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxHTTPDrainSize))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return NewErrHTTPStatus(resp, body)
+	}
+	return nil
+}
+
+// NewErrHTTPStatus creates an ErrHTTPStatus for the provided response and (the
+// beginning of) its body, which is truncated when it's too long. It allows
+// other HTTP based forwarders to report status errors which retry logic, like
+// DefaultRetryClassifier, understands.
+func NewErrHTTPStatus(resp *http.Response, body []byte) ErrHTTPStatus {
+	if len(body) > maxHTTPErrorBodySize {
+		body = body[:maxHTTPErrorBodySize]
+	}
+	return ErrHTTPStatus{
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// parseRetryAfter parses the Retry-After header which holds either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+package forwarders
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/serializers"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+type receivedRequest struct {
+	method string
+	header http.Header
+	body   []byte
+}
+
+func newHTTPTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, <-chan receivedRequest) {
+	t.Helper()
+
+	ch := make(chan receivedRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		ch <- receivedRequest{method: r.Method, header: r.Header.Clone(), body: body}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+func TestHTTPForwarder(t *testing.T) {
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusAccepted) }
+
+	t.Run("sends payload with configured method, headers and auth", func(t *testing.T) {
+		srv, received := newHTTPTestServer(t, ok)
+		hf, err := NewHTTPForwarder(srv.URL, logr.Discard(),
+			OptHTTPMethod(http.MethodPut),
+			OptHTTPHeader("X-Cluster", "test"),
+			OptHTTPContentType("text/plain"),
+			OptHTTPBearerToken("token"),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, hf.Forward(context.Background(), []byte("signal=test;key=value;")))
+		r := <-received
+		assert.Equal(t, http.MethodPut, r.method)
+		assert.Equal(t, "test", r.header.Get("X-Cluster"))
+		assert.Equal(t, "text/plain", r.header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.header.Get("Authorization"))
+		assert.Empty(t, r.header.Get("Content-Encoding"))
+		assert.Equal(t, []byte("signal=test;key=value;"), r.body)
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		srv, received := newHTTPTestServer(t, ok)
+		hf, err := NewHTTPForwarder(srv.URL, logr.Discard(), OptHTTPBasicAuth("user", "pass"))
+		require.NoError(t, err)
+
+		require.NoError(t, hf.Forward(context.Background(), []byte("payload")))
+		r := <-received
+		username, password, ok := (&http.Request{Header: r.header}).BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+		assert.Equal(t, defaultHTTPContentType, r.header.Get("Content-Type"))
+	})
+
+	t.Run("compressed payloads are sent with Content-Encoding", func(t *testing.T) {
+		srv, received := newHTTPTestServer(t, ok)
+		hf, err := NewHTTPForwarder(srv.URL, logr.Discard())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err = zw.Write([]byte("payload"))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		require.NoError(t, hf.Forward(context.Background(), buf.Bytes()))
+		r := <-received
+		assert.Equal(t, "gzip", r.header.Get("Content-Encoding"))
+		assert.Equal(t, buf.Bytes(), r.body)
+	})
+
+	t.Run("CloudEvents binary mode", func(t *testing.T) {
+		srv, received := newHTTPTestServer(t, ok)
+		hf, err := NewHTTPForwarder(srv.URL, logr.Discard(), OptHTTPCloudEventsBinaryMode())
+		require.NoError(t, err)
+
+		s, err := serializers.NewCloudEvents("/clusters/test")
+		require.NoError(t, err)
+		event, err := s.Serialize(types.Report{"wf": types.ProviderReport{"key": "value"}}, types.Signal("ping"))
+		require.NoError(t, err)
+
+		require.NoError(t, hf.Forward(context.Background(), event))
+		r := <-received
+		assert.Equal(t, "/clusters/test", r.header.Get("ce-source"))
+		assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+		assert.JSONEq(t, `{"wf":{"key":"value"}}`, string(r.body))
+	})
+
+	t.Run("error statuses are reported as ErrHTTPStatus", func(t *testing.T) {
+		srv, received := newHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/unavailable" {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("malformed report"))
+		})
+
+		hf, err := NewHTTPForwarder(srv.URL+"/unavailable", logr.Discard())
+		require.NoError(t, err)
+		err = hf.Forward(context.Background(), []byte("payload"))
+		<-received
+		var statusErr ErrHTTPStatus
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+		assert.Equal(t, 30*time.Second, statusErr.RetryAfter)
+		assert.True(t, statusErr.Retryable())
+
+		hf, err = NewHTTPForwarder(srv.URL+"/invalid", logr.Discard())
+		require.NoError(t, err)
+		err = hf.Forward(context.Background(), []byte("payload"))
+		<-received
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+		assert.Equal(t, []byte("malformed report"), statusErr.Body)
+		assert.False(t, statusErr.Retryable())
+	})
+
+	t.Run("endless response bodies are not read until the deadline", func(t *testing.T) {
+		srv, _ := newHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			chunk := bytes.Repeat([]byte("x"), 1024)
+			for r.Context().Err() == nil {
+				if _, err := w.Write(chunk); err != nil {
+					return
+				}
+			}
+		})
+		hf, err := NewHTTPForwarder(srv.URL, logr.Discard())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		start := time.Now()
+		require.NoError(t, hf.Forward(ctx, []byte("payload")))
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("requests go through the configured proxy", func(t *testing.T) {
+		proxy, received := newHTTPTestServer(t, ok)
+		proxyURL, err := url.Parse(proxy.URL)
+		require.NoError(t, err)
+
+		hf, err := NewHTTPForwarder("http://telemetry.example.com/reports", logr.Discard())
+		require.NoError(t, err)
+		// The default transport reads the proxy from the environment once per
+		// process, so point it at the test proxy explicitly.
+		transport, ok := hf.client.Transport.(*http.Transport)
+		require.True(t, ok)
+		require.NotNil(t, transport.Proxy)
+		transport.Proxy = http.ProxyURL(proxyURL)
+
+		require.NoError(t, hf.Forward(context.Background(), []byte("payload")))
+		r := <-received
+		assert.Equal(t, []byte("payload"), r.body)
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		_, err := NewHTTPForwarder("tcp://localhost:1234", logr.Discard())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,167 @@
+package forwarders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const defaultFailoverProbeInterval = time.Minute
+
+type fanOutForwarder struct {
+	forwarders []forwarder
+}
+
+// NewFanOutForwarder creates a forwarder which forwards every payload to all
+// the provided forwarders in parallel, e.g. to a primary and a secondary
+// collector during a migration.
+func NewFanOutForwarder(forwarders ...forwarder) (*fanOutForwarder, error) {
+	if err := validateForwarders(forwarders); err != nil {
+		return nil, err
+	}
+	return &fanOutForwarder{
+		forwarders: forwarders,
+	}, nil
+}
+
+// Name returns the name of the forwarder.
+func (ff *fanOutForwarder) Name() string {
+	return "FanOutForwarder(" + names(ff.forwarders) + ")"
+}
+
+// Forward forwards the payload to all forwarders and waits for them to finish.
+// It returns the errors of all forwarders which failed, joined.
+func (ff *fanOutForwarder) Forward(ctx context.Context, payload []byte) error {
+	errs := make([]error, len(ff.forwarders))
+	var wg sync.WaitGroup
+	for i, f := range ff.forwarders {
+		wg.Go(func() {
+			if err := f.Forward(ctx, payload); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", f.Name(), err)
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Close closes the forwarders which implement io.Closer.
+func (ff *fanOutForwarder) Close() error {
+	return closeForwarders(ff.forwarders...)
+}
+
+type failoverForwarder struct {
+	logger        logr.Logger
+	forwarders    []forwarder
+	probeInterval time.Duration
+	now           func() time.Time
+
+	// lock guards active and lastProbe.
+	lock      sync.Mutex
+	active    int
+	lastProbe time.Time
+}
+
+// NewFailoverForwarder creates a forwarder which forwards payloads to the first
+// healthy of the provided forwarders, in order of preference. When forwarding
+// fails, the next forwarders are tried and the forwarder sticks to the first
+// one which succeeds. While it's not using the preferred forwarder, it tries
+// the preferred ones again every probeInterval, a minute when 0, and switches
+// back once they succeed.
+func NewFailoverForwarder(probeInterval time.Duration, logger logr.Logger, forwarders ...forwarder) (*failoverForwarder, error) {
+	if err := validateForwarders(forwarders); err != nil {
+		return nil, err
+	}
+	if probeInterval < 0 {
+		return nil, errors.New("probe interval cannot be negative")
+	}
+	if probeInterval == 0 {
+		probeInterval = defaultFailoverProbeInterval
+	}
+	return &failoverForwarder{
+		logger:        logger,
+		forwarders:    forwarders,
+		probeInterval: probeInterval,
+		now:           time.Now,
+	}, nil
+}
+
+// Name returns the name of the forwarder.
+func (ff *failoverForwarder) Name() string {
+	return "FailoverForwarder(" + names(ff.forwarders) + ")"
+}
+
+// Forward forwards the payload with the first forwarder which succeeds. It
+// returns the errors of all forwarders, joined, when all of them failed.
+func (ff *failoverForwarder) Forward(ctx context.Context, payload []byte) error {
+	ff.lock.Lock()
+	start := ff.active
+	if start > 0 && ff.now().Sub(ff.lastProbe) >= ff.probeInterval {
+		// Probe the preferred forwarders.
+		start = 0
+		ff.lastProbe = ff.now()
+	}
+	ff.lock.Unlock()
+
+	var errs []error
+	for n := range len(ff.forwarders) {
+		i := (start + n) % len(ff.forwarders)
+		f := ff.forwarders[i]
+		err := f.Forward(ctx, payload)
+		if err == nil {
+			ff.setActive(i)
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", f.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the forwarders which implement io.Closer.
+func (ff *failoverForwarder) Close() error {
+	return closeForwarders(ff.forwarders...)
+}
+
+func (ff *failoverForwarder) setActive(i int) {
+	ff.lock.Lock()
+	defer ff.lock.Unlock()
+
+	if ff.active == i {
+		return
+	}
+	ff.logger.Info("switching forwarder",
+		"from", ff.forwarders[ff.active].Name(), "to", ff.forwarders[i].Name(),
+	)
+	if i > 0 && ff.active == 0 {
+		ff.lastProbe = ff.now()
+	}
+	ff.active = i
+}
+
+func validateForwarders(forwarders []forwarder) error {
+	if len(forwarders) == 0 {
+		return errors.New("at least one forwarder is required")
+	}
+	for i, f := range forwarders {
+		if f == nil {
+			return fmt.Errorf("forwarder %d cannot be nil", i)
+		}
+	}
+	return nil
+}
+
+func names(forwarders []forwarder) string {
+	n := make([]string, 0, len(forwarders))
+	for _, f := range forwarders {
+		n = append(n, f.Name())
+	}
+	return strings.Join(n, ",")
+}
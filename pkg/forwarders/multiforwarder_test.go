@@ -0,0 +1,113 @@
+package forwarders
+
+import (
+	"context"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOutForwarder(t *testing.T) {
+	ctx := context.Background()
+	primary := &unreliableForwarder{name: "primary"}
+	secondary := &unreliableForwarder{name: "secondary", err: ErrHTTPStatus{StatusCode: http.StatusBadGateway}}
+
+	ff, err := NewFanOutForwarder(primary, secondary)
+	require.NoError(t, err)
+	assert.Equal(t, "FanOutForwarder(primary,secondary)", ff.Name())
+
+	require.NoError(t, ff.Forward(ctx, []byte("report-0")))
+	assert.Equal(t, []string{"report-0"}, primary.received())
+	assert.Equal(t, []string{"report-0"}, secondary.received())
+
+	secondary.setDown(true)
+	err = ff.Forward(ctx, []byte("report-1"))
+	require.ErrorContains(t, err, "secondary")
+	var statusErr ErrHTTPStatus
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	assert.Equal(t, []string{"report-0", "report-1"}, primary.received())
+
+	_, err = NewFanOutForwarder()
+	require.Error(t, err)
+	_, err = NewFanOutForwarder(primary, nil)
+	require.Error(t, err)
+}
+
+func TestFailoverForwarder(t *testing.T) {
+	ctx := context.Background()
+	unreachable := syscall.ECONNREFUSED
+	primary := &unreliableForwarder{name: "primary", err: unreachable}
+	backup := &unreliableForwarder{name: "backup", err: unreachable}
+
+	ff, err := NewFailoverForwarder(time.Minute, logr.Discard(), primary, backup)
+	require.NoError(t, err)
+	assert.Equal(t, "FailoverForwarder(primary,backup)", ff.Name())
+	now := time.Now()
+	ff.now = func() time.Time { return now }
+
+	require.NoError(t, ff.Forward(ctx, []byte("report-0")))
+	assert.Equal(t, []string{"report-0"}, primary.received())
+
+	t.Log("fails over to the backup when the primary is down")
+	primary.setDown(true)
+	require.NoError(t, ff.Forward(ctx, []byte("report-1")))
+	assert.Equal(t, []string{"report-1"}, backup.received())
+
+	t.Log("sticks to the backup until the probe interval passes")
+	primary.setDown(false)
+	require.NoError(t, ff.Forward(ctx, []byte("report-2")))
+	assert.Equal(t, []string{"report-1", "report-2"}, backup.received())
+	assert.Equal(t, []string{"report-0"}, primary.received())
+
+	t.Log("switches back to the primary once it's probed successfully")
+	ff.now = func() time.Time { return now.Add(time.Minute) }
+	require.NoError(t, ff.Forward(ctx, []byte("report-3")))
+	require.NoError(t, ff.Forward(ctx, []byte("report-4")))
+	assert.Equal(t, []string{"report-0", "report-3", "report-4"}, primary.received())
+
+	t.Log("returns all errors when all forwarders fail")
+	primary.setDown(true)
+	backup.setDown(true)
+	err = ff.Forward(ctx, []byte("report-5"))
+	require.ErrorIs(t, err, unreachable)
+	require.ErrorContains(t, err, "primary")
+	require.ErrorContains(t, err, "backup")
+
+	_, err = NewFailoverForwarder(-time.Second, logr.Discard(), primary)
+	require.Error(t, err)
+	_, err = NewFailoverForwarder(0, logr.Discard())
+	require.Error(t, err)
+}
+
+type closingForwarder struct {
+	unreliableForwarder
+	closed int
+}
+
+func (f *closingForwarder) Close() error {
+	f.closed++
+	return nil
+}
+
+func TestForwardersPassCloseOn(t *testing.T) {
+	closing := &closingForwarder{unreliableForwarder: unreliableForwarder{name: "closing"}}
+	other := &unreliableForwarder{name: "other"}
+
+	retry, err := NewRetryForwarder(closing, logr.Discard())
+	require.NoError(t, err)
+	fanOut, err := NewFanOutForwarder(retry, other)
+	require.NoError(t, err)
+	failover, err := NewFailoverForwarder(0, logr.Discard(), fanOut, other)
+	require.NoError(t, err)
+	q, err := NewQueueForwarder(t.TempDir(), failover, logr.Discard())
+	require.NoError(t, err)
+
+	require.NoError(t, q.Close())
+	assert.Equal(t, 1, closing.closed)
+}
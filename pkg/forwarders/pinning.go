@@ -0,0 +1,100 @@
+package forwarders
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// spkiPinPrefix is the optional prefix of pins, as used by HTTP Public Key Pinning.
+const spkiPinPrefix = "sha256/"
+
+// ErrCertificatePinMismatch is returned when the certificate chain presented by
+// the server doesn't match any of the configured pins.
+type ErrCertificatePinMismatch struct {
+	// Presented holds the pins of the certificates in the verified chains of
+	// the server.
+	Presented []string
+}
+
+func (e ErrCertificatePinMismatch) Error() string {
+	return fmt.Sprintf("server certificate chain doesn't match any of the pins, presented: %s",
+		strings.Join(e.Presented, ", "),
+	)
+}
+
+// SPKIPin returns the pin of the certificate: the base64 encoded SHA-256 digest
+// of its DER encoded SubjectPublicKeyInfo. It's the same as
+//
+//	openssl x509 -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func SPKIPin(cert *x509.Certificate) string {
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// OptTLSForwarderPins returns an option that makes the forwarder refuse to send
+// reports unless the certificate chain of the server contains a certificate
+// matching one of the provided SPKI SHA-256 pins, see SPKIPin. Pins can have
+// the "sha256/" prefix. Several pins can be configured at once to rotate keys,
+// or to pin both the server's key and its CA's key.
+//
+// Pins are checked in addition to the regular verification of the certificate,
+// against the chains it verified, which can include the root CA from the trust
+// store. Certificates the server sends which aren't part of a verified chain
+// are ignored, and connections are refused when verification is skipped with
+// InsecureSkipVerify. A mismatch results in an ErrCertificatePinMismatch error.
+func OptTLSForwarderPins(pins ...string) OptTLSForwarder {
+	return func(tf *tlsForwarder) {
+		tf.pins = append(tf.pins, pins...)
+	}
+}
+
+// parsePins validates pins and removes their optional prefix.
+func parsePins(pins []string) ([]string, error) {
+	parsed := make([]string, 0, len(pins))
+	for _, pin := range pins {
+		pin = strings.TrimPrefix(pin, spkiPinPrefix)
+		digest, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(digest) != sha256.Size {
+			return nil, fmt.Errorf("invalid pin %q: it has to be a base64 encoded SHA-256 digest", pin)
+		}
+		parsed = append(parsed, pin)
+	}
+	return parsed, nil
+}
+
+// pinnedVerifyConnection returns a VerifyConnection function which checks pins
+// after the provided function, if any.
+func pinnedVerifyConnection(pins []string, next func(tls.ConnectionState) error) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if next != nil {
+			if err := next(cs); err != nil {
+				return err
+			}
+		}
+
+		// The server can send any certificate, e.g. the pinned CA appended to
+		// a chain issued by another CA, so only verified chains are checked.
+		if len(cs.VerifiedChains) == 0 {
+			return errors.New("server certificate chain wasn't verified, it can't be checked against pins")
+		}
+		var presented []string
+		for _, chain := range cs.VerifiedChains {
+			for _, cert := range chain {
+				pin := SPKIPin(cert)
+				if slices.Contains(pins, pin) {
+					return nil
+				}
+				if !slices.Contains(presented, pin) {
+					presented = append(presented, pin)
+				}
+			}
+		}
+		return ErrCertificatePinMismatch{Presented: presented}
+	}
+}
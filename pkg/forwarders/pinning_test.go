@@ -0,0 +1,138 @@
+package forwarders
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPinningTestServer starts a TLS server presenting the certificate and
+// returns its address.
+func newPinningTestServer(t *testing.T, cert tls.Certificate) string {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp4", "localhost:0", &tls.Config{
+		MinVersion:   tls.VersionTLS13,
+		Certificates: []tls.Certificate{cert},
+	})
+	require.NoError(t, err)
+	var conns sync.WaitGroup
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns.Go(func() {
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+			})
+		}
+	}()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		conns.Wait()
+	})
+	return listener.Addr().String()
+}
+
+func TestTLSForwarderPins(t *testing.T) {
+	ca, otherCA := newTestCA(t, "ca"), newTestCA(t, "other-ca")
+	certPEM, keyPEM := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	serverPin := SPKIPin(cert.Leaf)
+	addr := newPinningTestServer(t, cert)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	roots.AddCert(otherCA.cert)
+	trustCA := OptTLSForwarderTLSConfig(func(c *tls.Config) {
+		c.RootCAs = roots
+	})
+	otherPin := func() string {
+		digest := sha256.Sum256([]byte("other key"))
+		return base64.StdEncoding.EncodeToString(digest[:])
+	}()
+
+	forwardTo := func(t *testing.T, addr string, opts ...OptTLSForwarder) error {
+		t.Helper()
+		tf, err := NewTLSForwarderWithOptions(addr, logr.Discard(), opts...)
+		require.NoError(t, err)
+		return tf.Forward(context.Background(), []byte("report"))
+	}
+	forward := func(t *testing.T, opts ...OptTLSForwarder) error {
+		t.Helper()
+		return forwardTo(t, addr, opts...)
+	}
+
+	t.Run("server key pin", func(t *testing.T) {
+		require.NoError(t, forward(t, trustCA, OptTLSForwarderPins(serverPin)))
+	})
+
+	t.Run("CA key pin from the verified chain", func(t *testing.T) {
+		require.NoError(t, forward(t, trustCA, OptTLSForwarderPins("sha256/"+SPKIPin(ca.cert))))
+	})
+
+	t.Run("rotation with several pins", func(t *testing.T) {
+		require.NoError(t, forward(t, trustCA, OptTLSForwarderPins(otherPin, serverPin)))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		err := forward(t, trustCA, OptTLSForwarderPins(otherPin))
+		var mismatch ErrCertificatePinMismatch
+		require.ErrorAs(t, err, &mismatch)
+		assert.Contains(t, mismatch.Presented, serverPin)
+		assert.False(t, DefaultRetryClassifier(err))
+	})
+
+	t.Run("pinned CA appended to a chain issued by another CA", func(t *testing.T) {
+		otherPEM, otherKeyPEM := otherCA.issue(t, "server", x509.ExtKeyUsageServerAuth)
+		other, err := tls.X509KeyPair(otherPEM, otherKeyPEM)
+		require.NoError(t, err)
+		other.Certificate = append(other.Certificate, ca.cert.Raw)
+		otherAddr := newPinningTestServer(t, other)
+
+		err = forwardTo(t, otherAddr, trustCA, OptTLSForwarderPins(SPKIPin(ca.cert)))
+		var mismatch ErrCertificatePinMismatch
+		require.ErrorAs(t, err, &mismatch)
+		assert.NotContains(t, mismatch.Presented, SPKIPin(ca.cert))
+		require.NoError(t, forwardTo(t, otherAddr, trustCA, OptTLSForwarderPins(SPKIPin(otherCA.cert))))
+	})
+
+	t.Run("connections are refused when verification is skipped", func(t *testing.T) {
+		insecure := OptTLSForwarderTLSConfig(func(c *tls.Config) {
+			c.InsecureSkipVerify = true
+		})
+		require.Error(t, forward(t, insecure, OptTLSForwarderPins(serverPin)))
+	})
+
+	t.Run("pins are checked with reloaded certificates", func(t *testing.T) {
+		caFile := filepath.Join(t.TempDir(), "ca.crt")
+		require.NoError(t, os.WriteFile(caFile, ca.pem, 0o600))
+		r, err := NewFileCertificateReloader("", "", caFile)
+		require.NoError(t, err)
+
+		require.NoError(t, forward(t, OptTLSForwarderTLSConfig(r.TLSOpt()), OptTLSForwarderPins(SPKIPin(ca.cert))))
+		var mismatch ErrCertificatePinMismatch
+		require.ErrorAs(t, forward(t, OptTLSForwarderTLSConfig(r.TLSOpt()), OptTLSForwarderPins(otherPin)), &mismatch)
+	})
+
+	t.Run("invalid pins", func(t *testing.T) {
+		for _, pin := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+			_, err := NewTLSForwarderWithOptions("localhost:443", logr.Discard(), OptTLSForwarderPins(pin))
+			require.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package forwarders
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// ProxyFunc returns the URL of the proxy to use to connect to the target, or
+// nil to connect directly. The target has the https scheme and the forwarder's
+// address as host.
+type ProxyFunc func(target *url.URL) (*url.URL, error)
+
+// ProxyFromEnvironment returns the proxy configured with the HTTPS_PROXY and
+// NO_PROXY environment variables (or their lowercase versions). Like for HTTP
+// clients, connections to localhost are never proxied.
+func ProxyFromEnvironment() ProxyFunc {
+	return httpproxy.FromEnvironment().ProxyFunc()
+}
+
+// OptTLSForwarderProxy returns an option that makes the forwarder connect
+// through the proxy with the provided URL. Supported schemes are http and https
+// for HTTP CONNECT proxies and socks5 and socks5h for SOCKS5 proxies.
+// Credentials in the URL's user info are used to authenticate to the proxy.
+func OptTLSForwarderProxy(proxyURL *url.URL) OptTLSForwarder {
+	return OptTLSForwarderProxyFunc(func(*url.URL) (*url.URL, error) {
+		return proxyURL, nil
+	})
+}
+
+// OptTLSForwarderProxyFunc returns an option that makes the forwarder connect
+// through the proxy returned by the provided function, see OptTLSForwarderProxy.
+// Use ProxyFromEnvironment to take the proxy from the environment variables.
+func OptTLSForwarderProxyFunc(proxyFunc ProxyFunc) OptTLSForwarder {
+	return func(tf *tlsForwarder) {
+		tf.proxy = proxyFunc
+	}
+}
+
+// dialTCP connects to the address, through the configured proxy if any.
+func dialTCP(ctx context.Context, dialer *net.Dialer, proxyFunc ProxyFunc, address string) (net.Conn, error) {
+	if proxyFunc == nil {
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+	proxyURL, err := proxyFunc(&url.URL{Scheme: "https", Host: address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy: %w", err)
+	}
+	if proxyURL == nil {
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https":
+		return dialHTTPConnect(ctx, dialer, proxyURL, address)
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if u := proxyURL.User; u != nil {
+			password, _ := u.Password()
+			auth = &proxy.Auth{User: u.Username(), Password: password}
+		}
+		d, err := proxy.SOCKS5("tcp", hostPort(proxyURL), auth, dialer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SOCKS5 dialer: %w", err)
+		}
+		conn, err := d.(proxy.ContextDialer).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect through SOCKS5 proxy %s: %w", proxyURL.Host, err)
+		}
+		return conn, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+}
+
+// dialHTTPConnect opens a tunnel to the address with an HTTP CONNECT request.
+func dialHTTPConnect(ctx context.Context, dialer *net.Dialer, proxyURL *url.URL, address string) (_ net.Conn, err error) {
+	conn, err := dialer.DialContext(ctx, "tcp", hostPort(proxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %w", proxyURL.Host, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = conn.Close()
+		}
+	}()
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName: proxyURL.Hostname(),
+			MinVersion: tls.VersionTLS12,
+		})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("failed TLS handshake with proxy %s: %w", proxyURL.Host, err)
+		}
+		conn = tlsConn
+	}
+
+	// Interrupt the exchange with the proxy when the context is done.
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if u := proxyURL.User; u != nil {
+		password, _ := u.Password()
+		req.Header.Set("Proxy-Authorization",
+			"Basic "+base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+password)),
+		)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed to send CONNECT request to proxy %s: %w", proxyURL.Host, errors.Join(ctx.Err(), err))
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONNECT response from proxy %s: %w", proxyURL.Host, errors.Join(ctx.Err(), err))
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyURL.Host, address, resp.Status)
+	}
+	// Servers don't send anything before the client's TLS hello, so nothing
+	// should be buffered.
+	if br.Buffered() > 0 {
+		return nil, fmt.Errorf("proxy %s sent unexpected data after CONNECT response", proxyURL.Host)
+	}
+	if !stop() {
+		return nil, fmt.Errorf("failed to connect through proxy %s: %w", proxyURL.Host, ctx.Err())
+	}
+	return conn, nil
+}
+
+// hostPort returns the proxy's address, with the scheme's default port when
+// it's not specified.
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "1080"
+	switch u.Scheme {
+	case "http":
+		port = "80"
+	case "https":
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,276 @@
+package forwarders
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testProxy is a stand-in HTTP CONNECT or SOCKS5 proxy which tunnels all
+// connections to the backend, whatever the requested target, and records the
+// requested targets.
+type testProxy struct {
+	listener net.Listener
+	backend  string
+	username string
+	password string
+
+	lock    sync.Mutex
+	targets []string
+}
+
+func newTestProxy(t *testing.T, backend, username, password string, socks bool) *testProxy {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "localhost:0")
+	require.NoError(t, err)
+	p := &testProxy{
+		listener: listener,
+		backend:  backend,
+		username: username,
+		password: password,
+	}
+
+	var conns sync.WaitGroup
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns.Go(func() {
+				defer conn.Close()
+				var target string
+				if socks {
+					target, err = p.handshakeSOCKS5(conn)
+				} else {
+					target, err = p.handshakeHTTPConnect(conn)
+				}
+				if err != nil {
+					return
+				}
+				p.lock.Lock()
+				p.targets = append(p.targets, target)
+				p.lock.Unlock()
+				p.tunnel(conn)
+			})
+		}
+	}()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		conns.Wait()
+	})
+	return p
+}
+
+func (p *testProxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+func (p *testProxy) Targets() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]string(nil), p.targets...)
+}
+
+func (p *testProxy) handshakeHTTPConnect(conn net.Conn) (string, error) {
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil {
+		return "", err
+	}
+	if req.Method != http.MethodConnect {
+		_, _ = io.WriteString(conn, "HTTP/1.1 405 Method Not Allowed\r\n\r\n")
+		return "", errors.New("not a CONNECT request")
+	}
+	expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(p.username+":"+p.password))
+	if req.Header.Get("Proxy-Authorization") != expected {
+		_, _ = io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return "", errors.New("invalid credentials")
+	}
+	_, err = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	return req.Host, err
+}
+
+func (p *testProxy) handshakeSOCKS5(conn net.Conn) (string, error) {
+	r := bufio.NewReader(conn)
+	// Greeting: version, number of methods, methods. Only username/password
+	// authentication (2) is accepted.
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(r, make([]byte, header[1])); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write([]byte{5, 2}); err != nil {
+		return "", err
+	}
+
+	// Username/password: version, username length, username, password length, password.
+	readString := func() (string, error) {
+		n, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(r, b)
+		return string(b), err
+	}
+	if _, err := r.ReadByte(); err != nil {
+		return "", err
+	}
+	username, err := readString()
+	if err != nil {
+		return "", err
+	}
+	password, err := readString()
+	if err != nil {
+		return "", err
+	}
+	if username != p.username || password != p.password {
+		_, _ = conn.Write([]byte{1, 1})
+		return "", errors.New("invalid credentials")
+	}
+	if _, err := conn.Write([]byte{1, 0}); err != nil {
+		return "", err
+	}
+
+	// Request: version, command, reserved, address type, address, port.
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(r, req); err != nil {
+		return "", err
+	}
+	var host string
+	switch req[3] {
+	case 1:
+		ip := make([]byte, net.IPv4len)
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	case 3:
+		if host, err = readString(); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported address type %d", req[3])
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(r, port); err != nil {
+		return "", err
+	}
+	_, err = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), err
+}
+
+func (p *testProxy) tunnel(conn net.Conn) {
+	backend, err := net.Dial("tcp", p.backend)
+	if err != nil {
+		return
+	}
+	defer backend.Close()
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(conn, backend)
+		close(done)
+	}()
+	_, _ = io.Copy(backend, conn)
+	_ = backend.Close()
+	<-done
+}
+
+func TestTLSForwarderProxy(t *testing.T) {
+	const target = "telemetry.example.com:443"
+
+	srv := newTelemetryTestServer(t, "localhost:0")
+	received, _ := srv.RunTracked(t)
+	insecure := OptTLSForwarderTLSConfig(func(c *tls.Config) {
+		c.InsecureSkipVerify = true
+	})
+
+	forward := func(t *testing.T, opts ...OptTLSForwarder) error {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		tf, err := NewTLSForwarderWithOptions(target, logr.Discard(), append(opts, insecure)...)
+		require.NoError(t, err)
+		if err := tf.Forward(ctx, []byte("report")); err != nil {
+			return err
+		}
+		assertData(ctx, t, received, []string{"report"})
+		return nil
+	}
+
+	t.Run("HTTP CONNECT", func(t *testing.T) {
+		p := newTestProxy(t, srv.Addr(), "user", "secret", false)
+
+		require.NoError(t, forward(t, OptTLSForwarderProxy(&url.URL{
+			Scheme: "http",
+			Host:   p.Addr(),
+			User:   url.UserPassword("user", "secret"),
+		})))
+		assert.Equal(t, []string{target}, p.Targets())
+
+		err := forward(t, OptTLSForwarderProxy(&url.URL{
+			Scheme: "http",
+			Host:   p.Addr(),
+			User:   url.UserPassword("user", "wrong"),
+		}))
+		require.ErrorContains(t, err, "407")
+	})
+
+	t.Run("SOCKS5", func(t *testing.T) {
+		p := newTestProxy(t, srv.Addr(), "user", "secret", true)
+
+		require.NoError(t, forward(t, OptTLSForwarderProxy(&url.URL{
+			Scheme: "socks5",
+			Host:   p.Addr(),
+			User:   url.UserPassword("user", "secret"),
+		})))
+		assert.Equal(t, []string{target}, p.Targets())
+
+		err := forward(t, OptTLSForwarderProxy(&url.URL{
+			Scheme: "socks5",
+			Host:   p.Addr(),
+			User:   url.UserPassword("user", "wrong"),
+		}))
+		require.Error(t, err)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		p := newTestProxy(t, srv.Addr(), "user", "secret", false)
+		t.Setenv("HTTPS_PROXY", "http://user:secret@"+p.Addr())
+		t.Setenv("NO_PROXY", "internal.example.com")
+
+		require.NoError(t, forward(t, OptTLSForwarderProxyFunc(ProxyFromEnvironment())))
+		assert.Equal(t, []string{target}, p.Targets())
+
+		t.Setenv("NO_PROXY", ".example.com")
+		proxyURL, err := ProxyFromEnvironment()(&url.URL{Scheme: "https", Host: target})
+		require.NoError(t, err)
+		assert.Nil(t, proxyURL, "targets matching NO_PROXY should be dialled directly")
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		err := forward(t, OptTLSForwarderProxy(&url.URL{Scheme: "ftp", Host: "localhost:21"}))
+		require.ErrorContains(t, err, "unsupported proxy scheme")
+	})
+}
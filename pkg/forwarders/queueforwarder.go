@@ -0,0 +1,368 @@
+package forwarders
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kong/kubernetes-telemetry/pkg/log"
+)
+
+const (
+	defaultQueueMaxSize       = 64 << 20 // 64 MiB
+	defaultQueueMaxAge        = 7 * 24 * time.Hour
+	defaultQueueRetryInterval = 30 * time.Second
+
+	queueFilePrefix = "report-"
+	queueTmpPrefix  = ".tmp-"
+	// queueSeqDigits is the width of the zero padded sequence number in file
+	// names, so that names sort in queue order.
+	queueSeqDigits = 20
+)
+
+type queueEntry struct {
+	seq     uint64
+	size    int64
+	created time.Time
+}
+
+type queueForwarder struct {
+	logger    logr.Logger
+	dir       string
+	forwarder forwarder
+
+	maxSize       int64
+	maxAge        time.Duration
+	retryInterval time.Duration
+	classifier    RetryClassifier
+	now           func() time.Time
+
+	// lock guards entries, size and nextSeq.
+	lock    sync.Mutex
+	entries []queueEntry
+	size    int64
+	nextSeq uint64
+
+	notify chan struct{}
+	cancel func()
+	done   chan struct{}
+	once   sync.Once
+}
+
+// OptQueue is the option function type that can configure the queue forwarder.
+type OptQueue func(*queueForwarder)
+
+// OptQueueMaxSize returns an option that sets the maximum total size of queued
+// payloads in bytes, 64 MiB by default. The oldest payloads are dropped to make
+// room for new ones.
+func OptQueueMaxSize(size int64) OptQueue {
+	return func(q *queueForwarder) {
+		q.maxSize = size
+	}
+}
+
+// OptQueueMaxAge returns an option that sets how long payloads are kept in the
+// queue, 7 days by default. Older payloads are dropped.
+func OptQueueMaxAge(age time.Duration) OptQueue {
+	return func(q *queueForwarder) {
+		q.maxAge = age
+	}
+}
+
+// OptQueueRetryInterval returns an option that sets how long the queue waits
+// before trying to forward payloads again after a failure, 30s by default.
+// New payloads trigger an attempt right away.
+func OptQueueRetryInterval(interval time.Duration) OptQueue {
+	return func(q *queueForwarder) {
+		q.retryInterval = interval
+	}
+}
+
+// OptQueueRetryClassifier returns an option that sets the classifier deciding
+// which errors keep the payload in the queue, DefaultRetryClassifier by default.
+// Payloads failing with other errors are dropped, so that they don't block the
+// queue forever.
+func OptQueueRetryClassifier(classifier RetryClassifier) OptQueue {
+	return func(q *queueForwarder) {
+		q.classifier = classifier
+	}
+}
+
+// NewQueueForwarder creates a store-and-forward forwarder which writes received
+// payloads to a bounded queue in the provided directory and forwards them in
+// order with the provided forwarder in the background. Payloads stay in the
+// queue until they are forwarded, so they survive the endpoint being unreachable
+// and process restarts: payloads queued by a previous process are forwarded
+// as well.
+//
+// The directory is created if it doesn't exist and must not be shared with
+// other queues. Close stops forwarding.
+func NewQueueForwarder(dir string, f forwarder, logger logr.Logger, opts ...OptQueue) (*queueForwarder, error) {
+	if f == nil {
+		return nil, errors.New("forwarder cannot be nil")
+	}
+
+	q := &queueForwarder{
+		logger:        logger,
+		dir:           dir,
+		forwarder:     f,
+		maxSize:       defaultQueueMaxSize,
+		maxAge:        defaultQueueMaxAge,
+		retryInterval: defaultQueueRetryInterval,
+		classifier:    DefaultRetryClassifier,
+		now:           time.Now,
+		notify:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	switch {
+	case q.maxSize <= 0:
+		return nil, errors.New("queue maximum size has to be positive")
+	case q.maxAge <= 0:
+		return nil, errors.New("queue maximum age has to be positive")
+	case q.retryInterval <= 0:
+		return nil, errors.New("queue retry interval has to be positive")
+	case q.classifier == nil:
+		return nil, errors.New("retry classifier cannot be nil")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	go q.run(ctx)
+	q.trigger()
+	return q, nil
+}
+
+// Name returns the name of the forwarder.
+func (q *queueForwarder) Name() string {
+	return "QueueForwarder(" + q.forwarder.Name() + ")"
+}
+
+// Forward writes the received payload to the queue. It returns once the payload
+// is stored on disk, forwarding happens in the background.
+func (q *queueForwarder) Forward(_ context.Context, payload []byte) error {
+	size := int64(len(payload))
+	if size > q.maxSize {
+		return fmt.Errorf("payload of %d bytes exceeds the queue maximum size of %d bytes", size, q.maxSize)
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.dropLocked(size)
+
+	seq := q.nextSeq
+	tmp, err := os.CreateTemp(q.dir, queueTmpPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to queue report: %w", err)
+	}
+	_, err = tmp.Write(payload)
+	err = errors.Join(err, tmp.Sync(), tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), q.path(seq))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to queue report: %w", err)
+	}
+	// The renamed file only survives a crash once the directory is synced.
+	if err := syncDir(q.dir); err != nil {
+		_ = os.Remove(q.path(seq))
+		return fmt.Errorf("failed to queue report: %w", err)
+	}
+
+	q.nextSeq++
+	q.entries = append(q.entries, queueEntry{seq: seq, size: size, created: q.now()})
+	q.size += size
+	q.trigger()
+	return nil
+}
+
+// Pending returns the number of payloads waiting in the queue.
+func (q *queueForwarder) Pending() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.entries)
+}
+
+// Close stops forwarding queued payloads, waits for an ongoing forward to
+// finish and closes the wrapped forwarder if it implements io.Closer. Payloads
+// which weren't forwarded stay in the queue directory.
+func (q *queueForwarder) Close() error {
+	var err error
+	q.once.Do(func() {
+		q.cancel()
+		<-q.done
+		err = closeForwarders(q.forwarder)
+	})
+	return err
+}
+
+// load reads the queue left in the directory by a previous process.
+func (q *queueForwarder) load() error {
+	dirEntries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read queue directory: %w", err)
+	}
+
+	for _, de := range dirEntries {
+		name := de.Name()
+		if strings.HasPrefix(name, queueTmpPrefix) {
+			// Leftover of an interrupted write.
+			_ = os.Remove(filepath.Join(q.dir, name))
+			continue
+		}
+		if !de.Type().IsRegular() || !strings.HasPrefix(name, queueFilePrefix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimPrefix(name, queueFilePrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			return fmt.Errorf("failed to read queued report %s: %w", name, err)
+		}
+		q.entries = append(q.entries, queueEntry{seq: seq, size: info.Size(), created: info.ModTime()})
+		q.size += info.Size()
+		q.nextSeq = max(q.nextSeq, seq+1)
+	}
+	slices.SortFunc(q.entries, func(a, b queueEntry) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.dropLocked(0)
+	return nil
+}
+
+func (q *queueForwarder) run(ctx context.Context) {
+	defer close(q.done)
+
+	for {
+		var retry <-chan time.Time
+		if !q.drain(ctx) {
+			retry = time.After(q.retryInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.notify:
+		case <-retry:
+		}
+	}
+}
+
+// drain forwards queued payloads in order until the queue is empty or
+// forwarding fails. It returns false when forwarding failed.
+func (q *queueForwarder) drain(ctx context.Context) bool {
+	for ctx.Err() == nil {
+		q.lock.Lock()
+		q.dropLocked(0)
+		if len(q.entries) == 0 {
+			q.lock.Unlock()
+			return true
+		}
+		entry := q.entries[0]
+		q.lock.Unlock()
+
+		payload, err := os.ReadFile(q.path(entry.seq))
+		if err != nil {
+			q.logger.Error(err, "failed to read queued report, dropping it")
+			q.remove(entry.seq)
+			continue
+		}
+
+		if err := q.forwarder.Forward(ctx, payload); err != nil {
+			if ctx.Err() != nil {
+				return true
+			}
+			if q.classifier(err) {
+				q.logger.V(log.DebugLevel).Info("failed to forward queued report, will retry",
+					"forwarder", q.forwarder.Name(), "pending", q.Pending(), "error", err.Error(),
+				)
+				return false
+			}
+			q.logger.Error(err, "failed to forward queued report, dropping it", "forwarder", q.forwarder.Name())
+		}
+		q.remove(entry.seq)
+	}
+	return true
+}
+
+// dropLocked drops payloads older than the maximum age and the oldest payloads
+// until there is room for a payload of the provided size. It has to be called
+// with the lock held.
+func (q *queueForwarder) dropLocked(size int64) {
+	cutoff := q.now().Add(-q.maxAge)
+	for len(q.entries) > 0 {
+		oldest := q.entries[0]
+		if !oldest.created.Before(cutoff) && q.size+size <= q.maxSize {
+			return
+		}
+		q.logger.Info("dropping queued report", "age", q.now().Sub(oldest.created).String(), "size", oldest.size)
+		q.removeLocked(oldest.seq)
+	}
+}
+
+func (q *queueForwarder) remove(seq uint64) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.removeLocked(seq)
+}
+
+// removeLocked removes the payload from the queue. It has to be called with
+// the lock held.
+func (q *queueForwarder) removeLocked(seq uint64) {
+	i := slices.IndexFunc(q.entries, func(e queueEntry) bool { return e.seq == seq })
+	if i < 0 {
+		// Already dropped while it was being forwarded.
+		return
+	}
+	if err := os.Remove(q.path(seq)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		q.logger.Error(err, "failed to remove queued report")
+	}
+	q.size -= q.entries[i].size
+	q.entries = slices.Delete(q.entries, i, i+1)
+}
+
+// syncDir flushes the directory entries of the directory to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	return errors.Join(d.Sync(), d.Close())
+}
+
+func (q *queueForwarder) path(seq uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%s%0*d", queueFilePrefix, queueSeqDigits, seq))
+}
+
+func (q *queueForwarder) trigger() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,158 @@
+package forwarders
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreliableForwarder records forwarded payloads and fails while it's down.
+type unreliableForwarder struct {
+	name      string
+	lock      sync.Mutex
+	down      bool
+	err       error
+	forwarded []string
+}
+
+func (f *unreliableForwarder) Name() string {
+	if f.name != "" {
+		return f.name
+	}
+	return "unreliableForwarder"
+}
+
+func (f *unreliableForwarder) Forward(_ context.Context, payload []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.down {
+		return f.err
+	}
+	f.forwarded = append(f.forwarded, string(payload))
+	return nil
+}
+
+func (f *unreliableForwarder) setDown(down bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.down = down
+}
+
+func (f *unreliableForwarder) received() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]string(nil), f.forwarded...)
+}
+
+func TestQueueForwarder(t *testing.T) {
+	unreachable := fmt.Errorf("failed to connect to reporting server: %w", syscall.ECONNREFUSED)
+
+	t.Run("forwards queued payloads in order once downstream recovers", func(t *testing.T) {
+		f := &unreliableForwarder{down: true, err: unreachable}
+		q, err := NewQueueForwarder(t.TempDir(), f, logr.Discard(), OptQueueRetryInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		defer q.Close()
+		assert.Equal(t, "QueueForwarder(unreliableForwarder)", q.Name())
+
+		for i := range 3 {
+			require.NoError(t, q.Forward(context.Background(), fmt.Appendf(nil, "report-%d", i)))
+		}
+		assert.Never(t, func() bool { return len(f.received()) > 0 }, 50*time.Millisecond, 5*time.Millisecond)
+		assert.Equal(t, 3, q.Pending())
+
+		f.setDown(false)
+		require.Eventually(t, func() bool { return q.Pending() == 0 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, []string{"report-0", "report-1", "report-2"}, f.received())
+	})
+
+	t.Run("survives restarts", func(t *testing.T) {
+		dir := t.TempDir()
+		f := &unreliableForwarder{down: true, err: unreachable}
+		q, err := NewQueueForwarder(dir, f, logr.Discard())
+		require.NoError(t, err)
+		require.NoError(t, q.Forward(context.Background(), []byte("report-0")))
+		require.NoError(t, q.Forward(context.Background(), []byte("report-1")))
+		q.Close()
+
+		// Leftover of a write interrupted by the restart.
+		require.NoError(t, os.WriteFile(dir+"/"+queueTmpPrefix+"123", []byte("partial"), 0o600))
+
+		f.setDown(false)
+		q, err = NewQueueForwarder(dir, f, logr.Discard())
+		require.NoError(t, err)
+		defer q.Close()
+		require.NoError(t, q.Forward(context.Background(), []byte("report-2")))
+		require.Eventually(t, func() bool { return q.Pending() == 0 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, []string{"report-0", "report-1", "report-2"}, f.received())
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("drops oldest payloads when the queue is full", func(t *testing.T) {
+		f := &unreliableForwarder{down: true, err: unreachable}
+		q, err := NewQueueForwarder(t.TempDir(), f, logr.Discard(), OptQueueMaxSize(20))
+		require.NoError(t, err)
+		defer q.Close()
+
+		for i := range 3 {
+			require.NoError(t, q.Forward(context.Background(), fmt.Appendf(nil, "report-%d", i)))
+		}
+		assert.Equal(t, 2, q.Pending())
+		require.Error(t, q.Forward(context.Background(), make([]byte, 21)))
+	})
+
+	t.Run("drops payloads older than the maximum age", func(t *testing.T) {
+		f := &unreliableForwarder{down: true, err: unreachable}
+		q, err := NewQueueForwarder(t.TempDir(), f, logr.Discard(), OptQueueMaxAge(time.Hour))
+		require.NoError(t, err)
+		defer q.Close()
+
+		now := time.Now()
+		q.lock.Lock()
+		q.now = func() time.Time { return now }
+		q.lock.Unlock()
+		require.NoError(t, q.Forward(context.Background(), []byte("old")))
+
+		q.lock.Lock()
+		q.now = func() time.Time { return now.Add(2 * time.Hour) }
+		q.lock.Unlock()
+		require.NoError(t, q.Forward(context.Background(), []byte("new")))
+		assert.Equal(t, 1, q.Pending())
+	})
+
+	t.Run("drops payloads failing with errors which are not retryable", func(t *testing.T) {
+		f := &unreliableForwarder{down: true, err: ErrHTTPStatus{StatusCode: 400}}
+		q, err := NewQueueForwarder(t.TempDir(), f, logr.Discard())
+		require.NoError(t, err)
+		defer q.Close()
+
+		require.NoError(t, q.Forward(context.Background(), []byte("invalid")))
+		require.Eventually(t, func() bool { return q.Pending() == 0 }, time.Second, 5*time.Millisecond)
+		assert.Empty(t, f.received())
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		f := &unreliableForwarder{}
+		for _, opt := range []OptQueue{
+			OptQueueMaxSize(0),
+			OptQueueMaxAge(0),
+			OptQueueRetryInterval(0),
+			OptQueueRetryClassifier(nil),
+		} {
+			_, err := NewQueueForwarder(t.TempDir(), f, logr.Discard(), opt)
+			require.Error(t, err)
+		}
+		_, err := NewQueueForwarder(t.TempDir(), nil, logr.Discard())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,299 @@
+package forwarders
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kong/kubernetes-telemetry/pkg/log"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+const (
+	defaultRetryMaxAttempts    = 5
+	defaultRetryInitialBackoff = time.Second
+	defaultRetryMaxBackoff     = 30 * time.Second
+	defaultRetryMultiplier     = 2.0
+	defaultRetryJitter         = 0.2
+)
+
+// forwarder is the interface of telemetry.Forwarder, which can't be imported
+// here because telemetry tests use forwarders.
+type forwarder interface {
+	Name() string
+	Forward(context.Context, []byte) error
+}
+
+// rawForwarder is the interface of telemetry.RawForwarder.
+type rawForwarder interface {
+	Name() string
+	Forward(context.Context, types.SignalReport) error
+}
+
+// closeForwarders closes the forwarders which hold resources, like a kept open
+// connection, i.e. implement io.Closer. It's used by forwarders wrapping other
+// ones to pass Close on.
+func closeForwarders[F any](forwarders ...F) error {
+	var errs []error
+	for _, f := range forwarders {
+		if c, ok := any(f).(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RetryClassifier tells whether the forward which failed with the error should
+// be retried.
+type RetryClassifier func(error) bool
+
+// DefaultRetryClassifier retries errors which are likely transient: network
+// errors, timeouts, connections closed or reset by the server and errors which
+// tell so with a Retryable() method, like ErrHTTPStatus for 408, 429 and 5xx
+// responses. Other errors, e.g. TLS certificate verification failures, are not
+// retried.
+func DefaultRetryClassifier(err error) bool {
+	var retryable interface{ Retryable() bool }
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// ErrRetriesExhausted is returned by the retry forwarders when all attempts failed.
+type ErrRetriesExhausted struct {
+	// Attempts is the number of failed attempts.
+	Attempts int
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e ErrRetriesExhausted) Error() string {
+	return fmt.Sprintf("failed to forward report after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e ErrRetriesExhausted) Unwrap() error {
+	return e.Err
+}
+
+type retryConfig struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	classifier     RetryClassifier
+}
+
+// OptRetry is the option function type that can configure the retry forwarders.
+type OptRetry func(*retryConfig)
+
+// OptRetryMaxAttempts returns an option that sets the maximum number of attempts,
+// including the first one, 5 by default.
+func OptRetryMaxAttempts(attempts int) OptRetry {
+	return func(c *retryConfig) {
+		c.maxAttempts = attempts
+	}
+}
+
+// OptRetryBackoff returns an option that sets the delay before the first retry
+// and the maximum delay between retries, 1s and 30s by default. The delay is
+// multiplied by the multiplier, 2 by default, after every attempt.
+func OptRetryBackoff(initial, maxBackoff time.Duration, multiplier float64) OptRetry {
+	return func(c *retryConfig) {
+		c.initialBackoff = initial
+		c.maxBackoff = maxBackoff
+		c.multiplier = multiplier
+	}
+}
+
+// OptRetryJitter returns an option that sets the fraction, between 0 and 1, by
+// which delays are randomly shortened, 0.2 by default. Jitter prevents many
+// clients from retrying at the same time.
+func OptRetryJitter(jitter float64) OptRetry {
+	return func(c *retryConfig) {
+		c.jitter = jitter
+	}
+}
+
+// OptRetryClassifier returns an option that sets the classifier deciding which
+// errors are retried, DefaultRetryClassifier by default.
+func OptRetryClassifier(classifier RetryClassifier) OptRetry {
+	return func(c *retryConfig) {
+		c.classifier = classifier
+	}
+}
+
+func newRetryConfig(opts []OptRetry) (retryConfig, error) {
+	c := retryConfig{
+		maxAttempts:    defaultRetryMaxAttempts,
+		initialBackoff: defaultRetryInitialBackoff,
+		maxBackoff:     defaultRetryMaxBackoff,
+		multiplier:     defaultRetryMultiplier,
+		jitter:         defaultRetryJitter,
+		classifier:     DefaultRetryClassifier,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	switch {
+	case c.maxAttempts < 1:
+		return c, errors.New("maximum number of attempts has to be at least 1")
+	case c.initialBackoff < 0 || c.maxBackoff < c.initialBackoff:
+		return c, errors.New("backoff cannot be negative or greater than the maximum backoff")
+	case c.multiplier < 1:
+		return c, errors.New("backoff multiplier has to be at least 1")
+	case c.jitter < 0 || c.jitter > 1:
+		return c, errors.New("jitter has to be between 0 and 1")
+	case c.classifier == nil:
+		return c, errors.New("retry classifier cannot be nil")
+	}
+	return c, nil
+}
+
+// do calls forward until it succeeds, fails with an error which isn't retryable,
+// the attempts are exhausted or the context is done.
+func (c retryConfig) do(ctx context.Context, logger logr.Logger, name string, forward func(context.Context) error) error {
+	backoff := c.initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := forward(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if !c.classifier(err) {
+			return err
+		}
+		if attempt == c.maxAttempts {
+			return ErrRetriesExhausted{Attempts: attempt, Err: err}
+		}
+
+		delay := time.Duration(float64(backoff) * (1 - c.jitter*rand.Float64())) //nolint:gosec
+		// Honour the delay requested by the server, e.g. with Retry-After.
+		var statusErr ErrHTTPStatus
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+			delay = min(statusErr.RetryAfter, c.maxBackoff)
+		}
+		logger.V(log.DebugLevel).Info("failed to forward report, retrying",
+			"forwarder", name, "attempt", attempt, "delay", delay, "error", err.Error(),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+		backoff = min(time.Duration(float64(backoff)*c.multiplier), c.maxBackoff)
+	}
+}
+
+type retryForwarder struct {
+	logger    logr.Logger
+	config    retryConfig
+	forwarder forwarder
+}
+
+// NewRetryForwarder creates a forwarder which forwards reports with the provided
+// forwarder and retries failed attempts with exponential backoff and jitter.
+// Retrying stops when the context is done, e.g. when the consumer is closed.
+func NewRetryForwarder(f forwarder, logger logr.Logger, opts ...OptRetry) (*retryForwarder, error) {
+	if f == nil {
+		return nil, errors.New("forwarder cannot be nil")
+	}
+	config, err := newRetryConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &retryForwarder{
+		logger:    logger,
+		config:    config,
+		forwarder: f,
+	}, nil
+}
+
+// Name returns the name of the forwarder.
+func (rf *retryForwarder) Name() string {
+	return "RetryForwarder(" + rf.forwarder.Name() + ")"
+}
+
+// Forward forwards the received payload with the wrapped forwarder, retrying
+// failed attempts.
+func (rf *retryForwarder) Forward(ctx context.Context, payload []byte) error {
+	return rf.config.do(ctx, rf.logger, rf.forwarder.Name(), func(ctx context.Context) error {
+		return rf.forwarder.Forward(ctx, payload)
+	})
+}
+
+// Close closes the wrapped forwarder if it implements io.Closer.
+func (rf *retryForwarder) Close() error {
+	return closeForwarders(rf.forwarder)
+}
+
+type rawRetryForwarder struct {
+	logger    logr.Logger
+	config    retryConfig
+	forwarder rawForwarder
+}
+
+// NewRawRetryForwarder creates a raw forwarder which forwards reports with the
+// provided raw forwarder and retries failed attempts like NewRetryForwarder.
+func NewRawRetryForwarder(f rawForwarder, logger logr.Logger, opts ...OptRetry) (*rawRetryForwarder, error) {
+	if f == nil {
+		return nil, errors.New("forwarder cannot be nil")
+	}
+	config, err := newRetryConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &rawRetryForwarder{
+		logger:    logger,
+		config:    config,
+		forwarder: f,
+	}, nil
+}
+
+// Name returns the name of the forwarder.
+func (rf *rawRetryForwarder) Name() string {
+	return "RetryForwarder(" + rf.forwarder.Name() + ")"
+}
+
+// Forward forwards the received report with the wrapped raw forwarder, retrying
+// failed attempts.
+func (rf *rawRetryForwarder) Forward(ctx context.Context, report types.SignalReport) error {
+	return rf.config.do(ctx, rf.logger, rf.forwarder.Name(), func(ctx context.Context) error {
+		return rf.forwarder.Forward(ctx, report)
+	})
+}
+
+// Close closes the wrapped raw forwarder if it implements io.Closer.
+func (rf *rawRetryForwarder) Close() error {
+	return closeForwarders(rf.forwarder)
+}
@@ -0,0 +1,178 @@
+package forwarders
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+type failingForwarder struct {
+	errs     []error
+	attempts int
+}
+
+func (f *failingForwarder) Name() string {
+	return "failingForwarder"
+}
+
+func (f *failingForwarder) forward(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.attempts++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+type failingByteForwarder struct{ failingForwarder }
+
+func (f *failingByteForwarder) Forward(ctx context.Context, _ []byte) error {
+	return f.forward(ctx)
+}
+
+type failingRawForwarder struct{ failingForwarder }
+
+func (f *failingRawForwarder) Forward(ctx context.Context, _ types.SignalReport) error {
+	return f.forward(ctx)
+}
+
+func TestRetryForwarder(t *testing.T) {
+	var (
+		connRefused = fmt.Errorf("failed to connect to reporting server: %w",
+			&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+		)
+		unavailable = ErrHTTPStatus{StatusCode: http.StatusServiceUnavailable}
+		badRequest  = ErrHTTPStatus{StatusCode: http.StatusBadRequest}
+		fastBackoff = OptRetryBackoff(time.Millisecond, 10*time.Millisecond, 2)
+	)
+
+	t.Run("retries retryable errors until success", func(t *testing.T) {
+		f := &failingByteForwarder{failingForwarder{errs: []error{connRefused, unavailable}}}
+		rf, err := NewRetryForwarder(f, logr.Discard(), fastBackoff)
+		require.NoError(t, err)
+
+		require.NoError(t, rf.Forward(context.Background(), []byte("payload")))
+		assert.Equal(t, 3, f.attempts)
+		assert.Equal(t, "RetryForwarder(failingForwarder)", rf.Name())
+	})
+
+	t.Run("does not retry errors which are not retryable", func(t *testing.T) {
+		f := &failingByteForwarder{failingForwarder{errs: []error{badRequest}}}
+		rf, err := NewRetryForwarder(f, logr.Discard(), fastBackoff)
+		require.NoError(t, err)
+
+		err = rf.Forward(context.Background(), []byte("payload"))
+		var statusErr ErrHTTPStatus
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+		assert.Equal(t, 1, f.attempts)
+	})
+
+	t.Run("gives up after the maximum number of attempts", func(t *testing.T) {
+		f := &failingRawForwarder{failingForwarder{errs: []error{unavailable, unavailable, unavailable, unavailable}}}
+		rf, err := NewRawRetryForwarder(f, logr.Discard(), fastBackoff, OptRetryMaxAttempts(3))
+		require.NoError(t, err)
+
+		err = rf.Forward(context.Background(), types.SignalReport{})
+		var exhausted ErrRetriesExhausted
+		require.ErrorAs(t, err, &exhausted)
+		assert.Equal(t, 3, exhausted.Attempts)
+		var statusErr ErrHTTPStatus
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+		assert.Equal(t, 3, f.attempts)
+	})
+
+	t.Run("custom classifier", func(t *testing.T) {
+		errCustom := errors.New("custom")
+		f := &failingByteForwarder{failingForwarder{errs: []error{errCustom}}}
+		rf, err := NewRetryForwarder(f, logr.Discard(), fastBackoff,
+			OptRetryClassifier(func(err error) bool { return errors.Is(err, errCustom) }),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, rf.Forward(context.Background(), []byte("payload")))
+		assert.Equal(t, 2, f.attempts)
+	})
+
+	t.Run("stops retrying when the context is done", func(t *testing.T) {
+		f := &failingByteForwarder{failingForwarder{errs: []error{connRefused, connRefused}}}
+		rf, err := NewRetryForwarder(f, logr.Discard(), OptRetryBackoff(time.Hour, time.Hour, 2))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		start := time.Now()
+		err = rf.Forward(ctx, []byte("payload"))
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, err, connRefused)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, 1, f.attempts)
+	})
+
+	t.Run("honours Retry-After", func(t *testing.T) {
+		f := &failingByteForwarder{failingForwarder{errs: []error{
+			ErrHTTPStatus{StatusCode: http.StatusTooManyRequests, RetryAfter: 200 * time.Millisecond},
+		}}}
+		rf, err := NewRetryForwarder(f, logr.Discard(), OptRetryBackoff(time.Millisecond, time.Second, 2))
+		require.NoError(t, err)
+
+		start := time.Now()
+		require.NoError(t, rf.Forward(context.Background(), []byte("payload")))
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		f := &failingByteForwarder{}
+		for _, opt := range []OptRetry{
+			OptRetryMaxAttempts(0),
+			OptRetryBackoff(time.Second, time.Millisecond, 2),
+			OptRetryBackoff(time.Millisecond, time.Second, 0.5),
+			OptRetryJitter(2),
+			OptRetryClassifier(nil),
+		} {
+			_, err := NewRetryForwarder(f, logr.Discard(), opt)
+			require.Error(t, err)
+		}
+		_, err := NewRetryForwarder(nil, logr.Discard())
+		require.Error(t, err)
+	})
+}
+
+func TestDefaultRetryClassifier(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, retryable: true},
+		{name: "connection reset", err: fmt.Errorf("failed to send report: %w", syscall.ECONNRESET), retryable: true},
+		{name: "timeout", err: fmt.Errorf("failed: %w", context.DeadlineExceeded), retryable: true},
+		{name: "5xx", err: ErrHTTPStatus{StatusCode: http.StatusBadGateway}, retryable: true},
+		{name: "429", err: ErrHTTPStatus{StatusCode: http.StatusTooManyRequests}, retryable: true},
+		{name: "4xx", err: ErrHTTPStatus{StatusCode: http.StatusUnauthorized}, retryable: false},
+		{name: "certificate", err: &tls.CertificateVerificationError{Err: errors.New("bad")}, retryable: false},
+		{name: "other", err: errors.New("serialization failed"), retryable: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.retryable, DefaultRetryClassifier(tc.err))
+		})
+	}
+}
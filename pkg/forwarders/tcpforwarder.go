@@ -0,0 +1,84 @@
+package forwarders
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kong/kubernetes-telemetry/pkg/serializers"
+)
+
+type tcpForwarder struct {
+	logger logr.Logger
+
+	address string
+	framing serializers.SyslogFraming
+}
+
+// OptTCP is the option function type that can configure the TCP forwarder.
+type OptTCP func(*tcpForwarder)
+
+// OptTCPFraming returns an option that makes the forwarder frame payloads
+// before sending them, as defined in RFC 6587. By default, payloads are sent as
+// they are, which suits serializers that already frame messages, like the
+// syslog serializer. Payloads which already end with a LF are not terminated
+// with another one with non-transparent framing.
+func OptTCPFraming(framing serializers.SyslogFraming) OptTCP {
+	return func(f *tcpForwarder) {
+		f.framing = framing
+	}
+}
+
+// NewTCPForwarder creates a TCP forwarder which forwards received serialized
+// reports unencrypted to a TCP endpoint specified by the provided address, e.g.
+// a syslog server. Like the TLS forwarder, it connects for every report.
+func NewTCPForwarder(address string, logger logr.Logger, opts ...OptTCP) (*tcpForwarder, error) {
+	f := &tcpForwarder{
+		logger:  logger,
+		address: address,
+		framing: serializers.SyslogFramingNone,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	switch f.framing {
+	case serializers.SyslogFramingNone, serializers.SyslogFramingNonTransparent, serializers.SyslogFramingOctetCounting:
+	default:
+		return nil, fmt.Errorf("unsupported framing %q", f.framing)
+	}
+	return f, nil
+}
+
+// Name returns the name of the forwarder.
+func (f *tcpForwarder) Name() string {
+	return "TCPForwarder"
+}
+
+// Forward forwards the received payload to the configured TCP endpoint.
+func (f *tcpForwarder) Forward(ctx context.Context, payload []byte) error {
+	dialCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(dialCtx, "tcp", f.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to reporting server: %w", err)
+	}
+	return send(ctx, conn, f.frame(payload))
+}
+
+func (f *tcpForwarder) frame(payload []byte) []byte {
+	switch f.framing {
+	case serializers.SyslogFramingOctetCounting:
+		return append([]byte(strconv.Itoa(len(payload))+" "), payload...)
+	case serializers.SyslogFramingNonTransparent:
+		if !bytes.HasSuffix(payload, []byte("\n")) {
+			return append(bytes.Clone(payload), '\n')
+		}
+	case serializers.SyslogFramingNone:
+	}
+	return payload
+}
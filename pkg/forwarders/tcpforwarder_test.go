@@ -0,0 +1,109 @@
+package forwarders
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/serializers"
+)
+
+// newTCPTestServer returns the address of a TCP server which sends everything
+// it receives on a connection to the returned channel.
+func newTCPTestServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp4", "localhost:0")
+	require.NoError(t, err)
+	received := make(chan string, 10)
+	var conns sync.WaitGroup
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns.Go(func() {
+				defer conn.Close()
+				b, err := io.ReadAll(conn)
+				assert.NoError(t, err)
+				received <- string(b)
+			})
+		}
+	}()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		conns.Wait()
+	})
+	return listener.Addr().String(), received
+}
+
+func TestTCPForwarder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addr, received := newTCPTestServer(t)
+
+	testCases := []struct {
+		name     string
+		opts     []OptTCP
+		payload  string
+		expected string
+	}{
+		{
+			name:     "payload is sent as is by default",
+			payload:  "<14>1 - - - - ping - signal=ping;\n",
+			expected: "<14>1 - - - - ping - signal=ping;\n",
+		},
+		{
+			name:     "non-transparent framing",
+			opts:     []OptTCP{OptTCPFraming(serializers.SyslogFramingNonTransparent)},
+			payload:  "<14>1 - - - - ping - signal=ping;",
+			expected: "<14>1 - - - - ping - signal=ping;\n",
+		},
+		{
+			name:     "non-transparent framing of terminated payload",
+			opts:     []OptTCP{OptTCPFraming(serializers.SyslogFramingNonTransparent)},
+			payload:  "<14>1 - - - - ping - signal=ping;\n",
+			expected: "<14>1 - - - - ping - signal=ping;\n",
+		},
+		{
+			name:     "octet counting framing",
+			opts:     []OptTCP{OptTCPFraming(serializers.SyslogFramingOctetCounting)},
+			payload:  "<14>1 - - - - ping - signal=ping;",
+			expected: "33 <14>1 - - - - ping - signal=ping;",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := NewTCPForwarder(addr, logr.Discard(), tc.opts...)
+			require.NoError(t, err)
+			require.NoError(t, f.Forward(ctx, []byte(tc.payload)))
+			assertData(ctx, t, received, []string{tc.expected})
+		})
+	}
+
+	t.Run("unreachable server", func(t *testing.T) {
+		listener, err := net.Listen("tcp4", "localhost:0")
+		require.NoError(t, err)
+		closedAddr := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		f, err := NewTCPForwarder(closedAddr, logr.Discard())
+		require.NoError(t, err)
+		err = f.Forward(ctx, []byte("report"))
+		require.ErrorContains(t, err, "failed to connect to reporting server")
+		assert.True(t, DefaultRetryClassifier(err))
+	})
+
+	t.Run("unsupported framing", func(t *testing.T) {
+		_, err := NewTCPForwarder(addr, logr.Discard(), OptTCPFraming("json"))
+		require.Error(t, err)
+	})
+}
@@ -6,14 +6,24 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+
+	"github.com/kong/kubernetes-telemetry/pkg/log"
 )
 
 const (
 	defaultTimeout  = time.Second * 30
 	defaultDeadline = time.Minute
+	// connCheckTimeout is how long the forwarder waits for the server to report
+	// that it closed the kept open connection.
+	connCheckTimeout = time.Millisecond
+	// connCheckBufferSize and connCheckMaxReads limit how much data sent by the
+	// server is discarded when checking the kept open connection.
+	connCheckBufferSize = 4096
+	connCheckMaxReads   = 16
 )
 
 var defaultTLSConf = tls.Config{
@@ -25,25 +35,91 @@ type tlsForwarder struct {
 	logger logr.Logger
 
 	tlsConf *tls.Config
+	tlsOpts []TLSOpt
 	address string
+
+	proxy ProxyFunc
+	pins  []string
+
+	persistent  bool
+	keepAlive   time.Duration
+	idleTimeout time.Duration
+	now         func() time.Time
+
+	// lock guards conn and lastUsed which are only used in persistent mode.
+	lock     sync.Mutex
+	conn     *tls.Conn
+	lastUsed time.Time
 }
 
 // TLSOpt defines an option type that manipulates *tls.Config.
 type TLSOpt func(*tls.Config)
 
+// OptTLSForwarder is the option function type that can configure the TLS forwarder.
+type OptTLSForwarder func(*tlsForwarder)
+
+// OptTLSForwarderTLSConfig returns an option that manipulates the TLS
+// configuration with the provided TLSOpts. They are applied to a copy of the
+// configuration for every connection, so that they can change it between
+// connections, e.g. to use reloaded certificates.
+func OptTLSForwarderTLSConfig(tlsOpts ...TLSOpt) OptTLSForwarder {
+	return func(tf *tlsForwarder) {
+		tf.tlsOpts = append(tf.tlsOpts, tlsOpts...)
+	}
+}
+
+// OptTLSForwarderPersistent returns an option that makes the forwarder keep the
+// connection open between reports instead of connecting for every report.
+//
+// TCP keepalive probes are sent every keepAlive on the connection (a zero value
+// uses Go's default and a negative one disables them). A connection which hasn't
+// been used for idleTimeout is closed and a new one is opened for the next report
+// (a zero value disables the idle timeout). When the server has closed the
+// connection, the forwarder reconnects before sending the report. When writing
+// to a reused connection fails before any of the report was written, the
+// forwarder reconnects and sends the report again. When a part of it was already
+// written, the error is returned instead, so that the report isn't delivered
+// twice.
+//
+// The connection is only used for sending reports: data sent by the server, e.g.
+// acknowledgements, is read and discarded. Close closes the connection. Consumers
+// created with telemetry.OptConsumerCloseForwarder call it when they are closed.
+func OptTLSForwarderPersistent(keepAlive, idleTimeout time.Duration) OptTLSForwarder {
+	return func(tf *tlsForwarder) {
+		tf.persistent = true
+		tf.keepAlive = keepAlive
+		tf.idleTimeout = idleTimeout
+	}
+}
+
 // NewTLSForwarder creates a TLS forwarder which forwards received serialized reports
 // to a TLS endpoint specified by the provided address.
 func NewTLSForwarder(address string, logger logr.Logger, tlsOpts ...TLSOpt) (*tlsForwarder, error) {
-	tlsConf := defaultTLSConf.Clone()
-	for _, opt := range tlsOpts {
-		opt(tlsConf)
-	}
+	return NewTLSForwarderWithOptions(address, logger, OptTLSForwarderTLSConfig(tlsOpts...))
+}
 
-	return &tlsForwarder{
+// NewTLSForwarderWithOptions creates a TLS forwarder which forwards received
+// serialized reports to a TLS endpoint specified by the provided address and
+// configured with the provided options.
+func NewTLSForwarderWithOptions(address string, logger logr.Logger, opts ...OptTLSForwarder) (*tlsForwarder, error) {
+	tf := &tlsForwarder{
 		logger:  logger,
-		tlsConf: tlsConf,
+		tlsConf: defaultTLSConf.Clone(),
 		address: address,
-	}, nil
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(tf)
+	}
+
+	if len(tf.pins) > 0 {
+		pins, err := parsePins(tf.pins)
+		if err != nil {
+			return nil, err
+		}
+		tf.pins = pins
+	}
+	return tf, nil
 }
 
 // Name returns the name of the forwarder.
@@ -52,18 +128,150 @@ func (tf *tlsForwarder) Name() string {
 }
 
 // Forward forwards the received payload to the configured TLS endpoint.
-func (tf *tlsForwarder) Forward(ctx context.Context, payload []byte) (err error) {
-	conn, err := tls.DialWithDialer(
-		&net.Dialer{
-			Timeout: defaultTimeout,
-		},
-		"tcp",
-		tf.address,
-		tf.tlsConf,
-	)
+func (tf *tlsForwarder) Forward(ctx context.Context, payload []byte) error {
+	if tf.persistent {
+		return tf.forwardPersistent(ctx, payload)
+	}
+
+	conn, err := tf.dial(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to connect to reporting server: %w", err)
+		return err
 	}
+	return send(ctx, conn, payload)
+}
+
+// Close closes the connection kept open in persistent mode. It's a no-op otherwise.
+func (tf *tlsForwarder) Close() error {
+	tf.lock.Lock()
+	defer tf.lock.Unlock()
+	return tf.closeConn()
+}
+
+func (tf *tlsForwarder) forwardPersistent(ctx context.Context, payload []byte) error {
+	tf.lock.Lock()
+	defer tf.lock.Unlock()
+
+	if tf.conn != nil && !tf.usable() {
+		if err := tf.closeConn(); err != nil {
+			tf.logger.V(log.DebugLevel).Info("failed to close stale connection", "error", err.Error())
+		}
+	}
+
+	reused := tf.conn != nil
+	if !reused {
+		conn, err := tf.dial(ctx)
+		if err != nil {
+			return err
+		}
+		tf.conn = conn
+	}
+
+	n, err := write(ctx, tf.conn, payload)
+	if err != nil {
+		// TLS connections can't be used after a failed write.
+		if closeErr := tf.closeConn(); closeErr != nil {
+			tf.logger.V(log.DebugLevel).Info("failed to close broken connection", "error", closeErr.Error())
+		}
+		// The server could have received the part of the report which was
+		// written, so sending it again could deliver it twice.
+		if !reused || n > 0 || ctx.Err() != nil {
+			return err
+		}
+
+		tf.logger.V(log.DebugLevel).Info("failed to send report on existing connection, reconnecting", "error", err.Error())
+		conn, dialErr := tf.dial(ctx)
+		if dialErr != nil {
+			return dialErr
+		}
+		tf.conn = conn
+		if _, err := write(ctx, tf.conn, payload); err != nil {
+			if closeErr := tf.closeConn(); closeErr != nil {
+				tf.logger.V(log.DebugLevel).Info("failed to close broken connection", "error", closeErr.Error())
+			}
+			return err
+		}
+	}
+	tf.lastUsed = tf.now()
+	return nil
+}
+
+// usable tells whether the kept open connection can still be used: it hasn't
+// been idle for longer than the idle timeout and it hasn't been closed by the
+// server. It has to be called with the lock held.
+func (tf *tlsForwarder) usable() bool {
+	if tf.idleTimeout > 0 && tf.now().Sub(tf.lastUsed) > tf.idleTimeout {
+		return false
+	}
+
+	// Writes to a connection closed by the server usually succeed and the data is
+	// lost, so check whether it was closed with a read which returns quickly.
+	// A deadline in the past would fail the read without looking at the socket.
+	// Timeouts don't break TLS connections for reading.
+	if err := tf.conn.SetReadDeadline(time.Now().Add(connCheckTimeout)); err != nil {
+		return false
+	}
+	// Data sent by the server, if any, is discarded: it means that the server
+	// is still there, not that the connection should be replaced.
+	buf := make([]byte, connCheckBufferSize)
+	for range connCheckMaxReads {
+		n, err := tf.conn.Read(buf)
+		if err == nil {
+			tf.logger.V(log.DebugLevel).Info("discarded data sent by the server", "bytes", n)
+			continue
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	return true
+}
+
+// closeConn closes the kept open connection, if any. It has to be called with
+// the lock held.
+func (tf *tlsForwarder) closeConn() error {
+	if tf.conn == nil {
+		return nil
+	}
+	err := tf.conn.Close()
+	tf.conn = nil
+	return err
+}
+
+func (tf *tlsForwarder) dial(ctx context.Context) (*tls.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	rawConn, err := dialTCP(ctx, &net.Dialer{KeepAlive: tf.keepAlive}, tf.proxy, tf.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to reporting server: %w", err)
+	}
+
+	conn := tls.Client(rawConn, tf.config())
+	if err := conn.HandshakeContext(ctx); err != nil {
+		_ = rawConn.Close()
+		return nil, fmt.Errorf("failed to connect to reporting server: %w", err)
+	}
+	return conn, nil
+}
+
+// config returns the TLS configuration of a new connection.
+func (tf *tlsForwarder) config() *tls.Config {
+	tlsConf := tf.tlsConf.Clone()
+	for _, opt := range tf.tlsOpts {
+		opt(tlsConf)
+	}
+	if len(tf.pins) > 0 {
+		tlsConf.VerifyConnection = pinnedVerifyConnection(tf.pins, tlsConf.VerifyConnection)
+	}
+	if tlsConf.ServerName == "" {
+		if host, _, err := net.SplitHostPort(tf.address); err == nil {
+			tlsConf.ServerName = host
+		}
+	}
+	return tlsConf
+}
+
+// send writes the payload to the connection and closes it.
+func send(ctx context.Context, conn net.Conn, payload []byte) (err error) {
 	// Set named return value in defer to not swallow the error returned by Close()
 	// and use errors.Join() to preserve any previously returned error. Go assigns
 	// explicitly returned value to named value before executing the deferred function.
@@ -71,6 +279,14 @@ func (tf *tlsForwarder) Forward(ctx context.Context, payload []byte) (err error)
 		err = errors.Join(err, conn.Close())
 	}()
 
+	_, err = write(ctx, conn, payload)
+	return err
+}
+
+// write writes the payload to the connection before the context's deadline,
+// or the default deadline when the context has none. It returns the number of
+// bytes written.
+func write(ctx context.Context, conn net.Conn, payload []byte) (int, error) {
 	var deadline time.Time
 	if d, ok := ctx.Deadline(); ok {
 		deadline = d
@@ -78,11 +294,12 @@ func (tf *tlsForwarder) Forward(ctx context.Context, payload []byte) (err error)
 		deadline = time.Now().Add(defaultDeadline)
 	}
 	if err := conn.SetDeadline(deadline); err != nil {
-		return fmt.Errorf("failed to set report connection deadline: %w", err)
+		return 0, fmt.Errorf("failed to set report connection deadline: %w", err)
 	}
 
-	if _, err := conn.Write(payload); err != nil {
-		return fmt.Errorf("failed to send report: %w", err)
+	n, err := conn.Write(payload)
+	if err != nil {
+		return n, fmt.Errorf("failed to send report: %w", err)
 	}
-	return nil
+	return n, nil
 }
@@ -4,15 +4,22 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/bombsimon/logrusr/v3"
+	"github.com/go-logr/logr/funcr"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kong/kubernetes-telemetry/pkg/log"
 	"github.com/kong/kubernetes-telemetry/pkg/provider"
 	"github.com/kong/kubernetes-telemetry/pkg/serializers"
 	"github.com/kong/kubernetes-telemetry/pkg/telemetry"
@@ -199,6 +206,45 @@ func (ts telemetryServer) Run(ctx context.Context, t *testing.T) <-chan string {
 	return receivedData
 }
 
+// RunTracked accepts connections until the server is closed, like Run, and
+// returns the accepted connections. At the end of the test, it closes the
+// server and the connections and waits for their handlers to return.
+func (ts telemetryServer) RunTracked(t *testing.T) (<-chan string, func() []net.Conn) {
+	var (
+		lock     sync.Mutex
+		conns    []net.Conn
+		handlers sync.WaitGroup
+	)
+	receivedData := make(chan string)
+	go func() {
+		for {
+			conn, err := ts.listener.Accept()
+			if err != nil {
+				return
+			}
+			lock.Lock()
+			conns = append(conns, conn)
+			lock.Unlock()
+			handlers.Go(func() { handleConnection(t, conn, receivedData) })
+		}
+	}()
+	t.Cleanup(func() {
+		_ = ts.Close()
+		lock.Lock()
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		lock.Unlock()
+		handlers.Wait()
+	})
+
+	return receivedData, func() []net.Conn {
+		lock.Lock()
+		defer lock.Unlock()
+		return slices.Clone(conns)
+	}
+}
+
 func assertData(ctx context.Context, t *testing.T, receivedData <-chan string, expectedData []string) {
 	for _, expected := range expectedData {
 		select {
@@ -228,3 +274,96 @@ func handleConnection(t *testing.T, conn net.Conn, receivedData chan<- string) {
 		receivedData <- data
 	}
 }
+
+func TestTLSForwarderPersistent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	srv := newTelemetryTestServer(t, "localhost:0")
+	defer srv.Close()
+
+	received, conns := srv.RunTracked(t)
+	accepted := func() int {
+		return len(conns())
+	}
+
+	var discarded atomic.Bool
+	logger := funcr.New(func(_, args string) {
+		if strings.Contains(args, "discarded data sent by the server") {
+			discarded.Store(true)
+		}
+	}, funcr.Options{Verbosity: log.DebugLevel})
+
+	const idleTimeout = time.Minute
+	tf, err := NewTLSForwarderWithOptions(srv.Addr(), logger,
+		OptTLSForwarderTLSConfig(func(c *tls.Config) {
+			c.InsecureSkipVerify = true
+		}),
+		OptTLSForwarderPersistent(time.Second, idleTimeout),
+	)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, tf.Close()) }()
+	now := time.Now()
+	tf.now = func() time.Time { return now }
+	// connChecked polls the kept open connection until the condition holds,
+	// so that the client has received what the server did.
+	connChecked := func(condition func(usable bool) bool) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			tf.lock.Lock()
+			defer tf.lock.Unlock()
+			return condition(tf.usable())
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	t.Log("reports are sent on a single connection")
+	for _, payload := range []string{"report-1", "report-2", "report-3"} {
+		require.NoError(t, tf.Forward(ctx, []byte(payload)))
+		assertData(ctx, t, received, []string{payload})
+	}
+	assert.Equal(t, 1, accepted())
+
+	t.Log("forwarder reconnects when the server closes the connection")
+	require.NoError(t, conns()[0].Close())
+	connChecked(func(usable bool) bool { return !usable })
+	require.NoError(t, tf.Forward(ctx, []byte("report-4")))
+	assertData(ctx, t, received, []string{"report-4"})
+	assert.Equal(t, 2, accepted())
+
+	t.Log("forwarder reconnects after the idle timeout")
+	now = now.Add(idleTimeout + time.Second)
+	require.NoError(t, tf.Forward(ctx, []byte("report-5")))
+	assertData(ctx, t, received, []string{"report-5"})
+	assert.Equal(t, 3, accepted())
+
+	t.Log("data sent by the server doesn't make the forwarder reconnect")
+	_, err = conns()[2].Write([]byte("ack"))
+	require.NoError(t, err)
+	connChecked(func(usable bool) bool { return usable && discarded.Load() })
+	require.NoError(t, tf.Forward(ctx, []byte("report-6")))
+	assertData(ctx, t, received, []string{"report-6"})
+	assert.Equal(t, 3, accepted())
+
+	t.Log("forwarder can be used concurrently")
+	const n = 10
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			assert.NoError(t, tf.Forward(ctx, []byte(fmt.Sprintf("concurrent-%d;", i))))
+		})
+	}
+	var all strings.Builder
+	for all.Len() < len("concurrent-0;")*n {
+		select {
+		case data := <-received:
+			all.WriteString(data)
+		case <-ctx.Done():
+			require.FailNow(t, "timeout waiting for data")
+		}
+	}
+	wg.Wait()
+	for i := range n {
+		assert.Contains(t, all.String(), fmt.Sprintf("concurrent-%d;", i))
+	}
+	assert.Equal(t, 3, accepted())
+}
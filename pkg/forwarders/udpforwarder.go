@@ -0,0 +1,53 @@
+package forwarders
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/go-logr/logr"
+)
+
+// maxUDPPayloadSize is the maximum size of an IPv4 UDP datagram's payload.
+const maxUDPPayloadSize = 65507
+
+type udpForwarder struct {
+	logger logr.Logger
+
+	address string
+}
+
+// NewUDPForwarder creates a UDP forwarder which forwards every received
+// serialized report unencrypted in a single datagram to a UDP endpoint specified
+// by the provided address, e.g. a syslog server (RFC 5426). Syslog messages
+// shouldn't be framed, see serializers.SyslogFramingNone.
+//
+// UDP doesn't acknowledge datagrams, so reports can be lost without an error.
+func NewUDPForwarder(address string, logger logr.Logger) (*udpForwarder, error) {
+	return &udpForwarder{
+		logger:  logger,
+		address: address,
+	}, nil
+}
+
+// Name returns the name of the forwarder.
+func (f *udpForwarder) Name() string {
+	return "UDPForwarder"
+}
+
+// Forward forwards the received payload to the configured UDP endpoint.
+func (f *udpForwarder) Forward(ctx context.Context, payload []byte) error {
+	if len(payload) > maxUDPPayloadSize {
+		return fmt.Errorf("payload of %d bytes exceeds the maximum UDP datagram size of %d bytes",
+			len(payload), maxUDPPayloadSize,
+		)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(dialCtx, "udp", f.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to reporting server: %w", err)
+	}
+	return send(ctx, conn, payload)
+}
@@ -0,0 +1,43 @@
+package forwarders
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/serializers"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestUDPForwarder(t *testing.T) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := serializers.NewSyslog(serializers.NewSemicolonDelimited(serializers.OptSemicolonDelimitedNoPriority()),
+		serializers.OptSyslogFraming(serializers.SyslogFramingNone),
+	)
+	require.NoError(t, err)
+	payload, err := s.Serialize(types.Report{"wf": types.ProviderReport{"key": "value"}}, "ping")
+	require.NoError(t, err)
+
+	f, err := NewUDPForwarder(conn.LocalAddr().String(), logr.Discard())
+	require.NoError(t, err)
+	require.NoError(t, f.Forward(context.Background(), payload))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, maxUDPPayloadSize)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, payload, buf[:n])
+
+	t.Run("payload larger than a datagram", func(t *testing.T) {
+		err := f.Forward(context.Background(), make([]byte, maxUDPPayloadSize+1))
+		require.Error(t, err)
+	})
+}
@@ -82,7 +82,7 @@ func (m meshDeploymentResults) ToProviderReport() types.ProviderReport {
 	sort.Strings(signals)
 
 	return types.ProviderReport{
-		"mdep": strings.Join(signals, ","),
+		DeploymentReportKey: strings.Join(signals, ","),
 	}
 }
 
@@ -204,7 +204,7 @@ func (m meshRunUnderResults) ToProviderReport() types.ProviderReport {
 	sort.Strings(signals)
 	value := strings.Join(signals, ",")
 	return types.ProviderReport{
-		"kinm": value,
+		RunUnderReportKey: value,
 	}
 }
 
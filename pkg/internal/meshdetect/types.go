@@ -8,6 +8,18 @@ import (
 	"github.com/kong/kubernetes-telemetry/pkg/types"
 )
 
+const (
+	// DeploymentReportKey is the report key under which mesh deployment signals
+	// are reported.
+	DeploymentReportKey = types.ProviderReportKey("mdep")
+	// RunUnderReportKey is the report key under which signals of the pod running
+	// under a mesh are reported.
+	RunUnderReportKey = types.ProviderReportKey("kinm")
+	// ServiceDistributionReportKey is the report key under which the distribution
+	// of services across meshes is reported.
+	ServiceDistributionReportKey = types.ProviderReportKey("mdist")
+)
+
 // DeploymentResults is the result of detecting signals of whether a certain
 // mesh is deployed in kubernetes cluster.
 type DeploymentResults struct {
@@ -60,6 +72,6 @@ func (s *ServiceDistributionResults) ToProviderReport() types.ProviderReport {
 	}
 
 	return types.ProviderReport{
-		"mdist": value,
+		ServiceDistributionReportKey: value,
 	}
 }
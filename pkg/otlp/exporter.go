@@ -0,0 +1,220 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	collectormetricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kong/kubernetes-telemetry/pkg/forwarders"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+const (
+	protobufContentType = "application/x-protobuf"
+	// maxResponseSize limits how much of an export response is read, so that
+	// a misbehaving collector can't make the exporter buffer any amount of data.
+	maxResponseSize = 64 * 1024
+)
+
+// ErrPartialSuccess is returned when the collector accepted the export request
+// but rejected some of its data points.
+type ErrPartialSuccess struct {
+	RejectedDataPoints int64
+	Message            string
+}
+
+func (e ErrPartialSuccess) Error() string {
+	return fmt.Sprintf("collector rejected %d data points: %s", e.RejectedDataPoints, e.Message)
+}
+
+// ErrGRPCStatus is returned by the gRPC exporter when the collector responds
+// with a status other than OK.
+type ErrGRPCStatus struct {
+	// Code is the gRPC status code of the response.
+	Code codes.Code
+	// Message is the status message of the response.
+	Message string
+}
+
+func (e ErrGRPCStatus) Error() string {
+	return fmt.Sprintf("export request failed with status %s: %s", e.Code, e.Message)
+}
+
+// Retryable returns true when the export can be retried, as defined by the
+// OTLP specification: the collector is unavailable, overloaded or the request
+// timed out or was aborted.
+func (e ErrGRPCStatus) Retryable() bool {
+	switch e.Code { //nolint:exhaustive
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+func checkResponse(resp *collectormetricsv1.ExportMetricsServiceResponse) error {
+	if ps := resp.GetPartialSuccess(); ps.GetRejectedDataPoints() > 0 || ps.GetErrorMessage() != "" {
+		return ErrPartialSuccess{
+			RejectedDataPoints: ps.GetRejectedDataPoints(),
+			Message:            ps.GetErrorMessage(),
+		}
+	}
+	return nil
+}
+
+func (cfg exporterConfig) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, cfg.timeout)
+}
+
+type httpExporter struct {
+	cfg      exporterConfig
+	endpoint string
+}
+
+// NewHTTPExporter creates a raw forwarder which exports reports as metrics to
+// an OTLP/HTTP endpoint using the binary Protobuf encoding. The endpoint is the
+// full URL of the metrics endpoint, e.g. "https://collector:4318/v1/metrics".
+// See NewMetricsRequest for how reports are mapped onto metrics.
+//
+// Responses with a status code other than 2xx are reported as
+// forwarders.ErrHTTPStatus, so that they can be retried with
+// forwarders.NewRawRetryForwarder.
+func NewHTTPExporter(endpoint string, opts ...OptExporter) (*httpExporter, error) {
+	if endpoint == "" {
+		return nil, errors.New("endpoint cannot be empty")
+	}
+	return &httpExporter{
+		cfg:      newExporterConfig(opts),
+		endpoint: endpoint,
+	}, nil
+}
+
+// Name returns the name of the exporter.
+func (e *httpExporter) Name() string {
+	return "OTLPHTTPExporter"
+}
+
+// Forward exports the report to the configured endpoint.
+func (e *httpExporter) Forward(ctx context.Context, sr types.SignalReport) error {
+	req, err := e.cfg.metricsRequest(sr)
+	if err != nil {
+		return fmt.Errorf("failed to map report onto metrics: %w", err)
+	}
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal export request: %w", err)
+	}
+
+	ctx, cancel := e.cfg.withTimeout(ctx)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create export request: %w", err)
+	}
+	for k, v := range e.cfg.headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set("Content-Type", protobufContentType)
+
+	httpResp, err := e.cfg.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send export request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read export response: %w", err)
+	}
+	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
+		return forwarders.NewErrHTTPStatus(httpResp, respBody)
+	}
+	if len(respBody) > maxResponseSize {
+		return fmt.Errorf("export response exceeds %d bytes", maxResponseSize)
+	}
+
+	var resp collectormetricsv1.ExportMetricsServiceResponse
+	if err := proto.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("failed to unmarshal export response: %w", err)
+	}
+	return checkResponse(&resp)
+}
+
+type grpcExporter struct {
+	cfg    exporterConfig
+	conn   *grpc.ClientConn
+	client collectormetricsv1.MetricsServiceClient
+}
+
+// NewGRPCExporter creates a raw forwarder which exports reports as metrics to
+// an OTLP/gRPC endpoint, e.g. "collector:4317". TLS with system roots is used
+// unless transport credentials are provided with OptExporterGRPCDialOptions.
+// See NewMetricsRequest for how reports are mapped onto metrics.
+//
+// The connection is established lazily and has to be released with Close.
+// Error statuses returned by the collector are reported as ErrGRPCStatus.
+func NewGRPCExporter(target string, opts ...OptExporter) (*grpcExporter, error) {
+	cfg := newExporterConfig(opts)
+	dialOpts := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})),
+	}, cfg.grpcDialOptions...)
+
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	return &grpcExporter{
+		cfg:    cfg,
+		conn:   conn,
+		client: collectormetricsv1.NewMetricsServiceClient(conn),
+	}, nil
+}
+
+// Name returns the name of the exporter.
+func (e *grpcExporter) Name() string {
+	return "OTLPGRPCExporter"
+}
+
+// Forward exports the report to the configured endpoint.
+func (e *grpcExporter) Forward(ctx context.Context, sr types.SignalReport) error {
+	req, err := e.cfg.metricsRequest(sr)
+	if err != nil {
+		return fmt.Errorf("failed to map report onto metrics: %w", err)
+	}
+
+	ctx, cancel := e.cfg.withTimeout(ctx)
+	defer cancel()
+	if len(e.cfg.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(e.cfg.headers))
+	}
+
+	resp, err := e.client.Export(ctx, req)
+	if err != nil {
+		if s, ok := status.FromError(err); ok {
+			return ErrGRPCStatus{Code: s.Code(), Message: s.Message()}
+		}
+		return fmt.Errorf("export request failed: %w", err)
+	}
+	return checkResponse(resp)
+}
+
+// Close closes the connection to the endpoint.
+func (e *grpcExporter) Close() error {
+	return e.conn.Close()
+}
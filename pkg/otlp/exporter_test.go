@@ -0,0 +1,205 @@
+package otlp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kong/kubernetes-telemetry/pkg/forwarders"
+)
+
+// fakeCollector is an in-process OTLP collector which records received requests.
+type fakeCollector struct {
+	collectormetricsv1.UnimplementedMetricsServiceServer
+
+	requests chan *collectormetricsv1.ExportMetricsServiceRequest
+	headers  chan string
+	response *collectormetricsv1.ExportMetricsServiceResponse
+	err      error
+}
+
+func newFakeCollector() *fakeCollector {
+	return &fakeCollector{
+		requests: make(chan *collectormetricsv1.ExportMetricsServiceRequest, 1),
+		headers:  make(chan string, 1),
+		response: &collectormetricsv1.ExportMetricsServiceResponse{},
+	}
+}
+
+func (c *fakeCollector) Export(
+	ctx context.Context, req *collectormetricsv1.ExportMetricsServiceRequest,
+) (*collectormetricsv1.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	c.headers <- firstOrEmpty(md.Get("x-api-key"))
+	c.requests <- req
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.response, nil
+}
+
+func (c *fakeCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != protobufContentType {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var req collectormetricsv1.ExportMetricsServiceRequest
+	if err := proto.Unmarshal(b, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.headers <- r.Header.Get("X-Api-Key")
+	c.requests <- &req
+
+	resp, _ := proto.Marshal(c.response)
+	w.Header().Set("Content-Type", protobufContentType)
+	_, _ = w.Write(resp)
+}
+
+func firstOrEmpty(l []string) string {
+	if len(l) == 0 {
+		return ""
+	}
+	return l[0]
+}
+
+func TestHTTPExporter(t *testing.T) {
+	collector := newFakeCollector()
+	srv := httptest.NewServer(collector)
+	t.Cleanup(srv.Close)
+
+	e, err := NewHTTPExporter(srv.URL+"/v1/metrics", OptExporterHeaders(map[string]string{"X-Api-Key": "secret"}))
+	require.NoError(t, err)
+
+	require.NoError(t, e.Forward(t.Context(), testSignalReport()))
+	assert.Equal(t, "secret", <-collector.headers)
+	req := <-collector.requests
+	require.Len(t, req.GetResourceMetrics()[0].GetScopeMetrics(), 2)
+
+	t.Run("partial success", func(t *testing.T) {
+		collector.response = &collectormetricsv1.ExportMetricsServiceResponse{
+			PartialSuccess: &collectormetricsv1.ExportMetricsPartialSuccess{RejectedDataPoints: 2, ErrorMessage: "nope"},
+		}
+		t.Cleanup(func() { collector.response = &collectormetricsv1.ExportMetricsServiceResponse{} })
+
+		err := e.Forward(t.Context(), testSignalReport())
+		<-collector.headers
+		<-collector.requests
+		var partial ErrPartialSuccess
+		require.ErrorAs(t, err, &partial)
+		assert.Equal(t, int64(2), partial.RejectedDataPoints)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		e, err := NewHTTPExporter(srv.URL + "/wrong")
+		require.NoError(t, err)
+		err = e.Forward(t.Context(), testSignalReport())
+		var statusErr forwarders.ErrHTTPStatus
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+		assert.False(t, forwarders.DefaultRetryClassifier(err))
+	})
+
+	t.Run("retryable error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "7")
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+		e, err := NewHTTPExporter(srv.URL + "/v1/metrics")
+		require.NoError(t, err)
+
+		err = e.Forward(t.Context(), testSignalReport())
+		var statusErr forwarders.ErrHTTPStatus
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, 7*time.Second, statusErr.RetryAfter)
+		assert.True(t, forwarders.DefaultRetryClassifier(err))
+	})
+
+	t.Run("endless response body", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			chunk := make([]byte, 1024)
+			for r.Context().Err() == nil {
+				if _, err := w.Write(chunk); err != nil {
+					return
+				}
+			}
+		}))
+		t.Cleanup(srv.Close)
+		e, err := NewHTTPExporter(srv.URL + "/v1/metrics")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+		defer cancel()
+		err = e.Forward(ctx, testSignalReport())
+		require.ErrorContains(t, err, "export response exceeds")
+		require.NoError(t, ctx.Err())
+	})
+}
+
+func TestGRPCExporter(t *testing.T) {
+	collector := newFakeCollector()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	collectormetricsv1.RegisterMetricsServiceServer(srv, collector)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+
+	e, err := NewGRPCExporter(l.Addr().String(),
+		OptExporterHeaders(map[string]string{"x-api-key": "secret"}),
+		OptExporterGRPCDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, e.Close()) })
+
+	require.NoError(t, e.Forward(t.Context(), testSignalReport()))
+	assert.Equal(t, "secret", <-collector.headers)
+	req := <-collector.requests
+	require.Len(t, req.GetResourceMetrics()[0].GetScopeMetrics(), 2)
+	assert.Equal(t, "identify-platform", req.GetResourceMetrics()[0].GetScopeMetrics()[0].GetScope().GetName())
+	t.Run("error status", func(t *testing.T) {
+		testCases := []struct {
+			code      codes.Code
+			retryable bool
+		}{
+			{code: codes.Unavailable, retryable: true},
+			{code: codes.ResourceExhausted, retryable: true},
+			{code: codes.InvalidArgument, retryable: false},
+			{code: codes.Unauthenticated, retryable: false},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.code.String(), func(t *testing.T) {
+				collector.err = status.Error(tc.code, "nope")
+				t.Cleanup(func() { collector.err = nil })
+
+				err := e.Forward(t.Context(), testSignalReport())
+				<-collector.headers
+				<-collector.requests
+				var statusErr ErrGRPCStatus
+				require.ErrorAs(t, err, &statusErr)
+				assert.Equal(t, tc.code, statusErr.Code)
+				assert.Equal(t, tc.retryable, forwarders.DefaultRetryClassifier(err))
+			})
+		}
+	})
+}
@@ -0,0 +1,213 @@
+// Package otlp exports telemetry reports as OpenTelemetry metrics using the
+// OpenTelemetry Protocol (OTLP) over HTTP or gRPC.
+package otlp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	collectormetricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcev1 "go.opentelemetry.io/proto/otlp/resource/v1"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// SignalAttributeKey is the data point attribute holding the report's signal.
+const SignalAttributeKey = "signal"
+
+// durationUnit is the unit of gauges which hold durations.
+const durationUnit = "s"
+
+// NewMetricsRequest maps the signal report onto an OTLP metrics export request.
+//
+// Every workflow becomes an instrumentation scope named after the workflow.
+// Numeric values become gauges named after their keys (keys of nested reports
+// are joined with a dot) with a single data point each:
+//   - integers are int gauges,
+//   - floats are double gauges,
+//   - durations are double gauges in seconds,
+//   - booleans are int gauges with 0 for false and 1 for true.
+//
+// All other values (strings, string lists and times) become attributes of all
+// data points of their workflow or, with OptExporterStringsAsResourceAttributes,
+// resource attributes named "<workflow>.<key>". Every data point has the signal
+// set as SignalAttributeKey attribute.
+func NewMetricsRequest(sr types.SignalReport, opts ...OptExporter) (*collectormetricsv1.ExportMetricsServiceRequest, error) {
+	cfg := newExporterConfig(opts)
+	return cfg.metricsRequest(sr)
+}
+
+func (cfg exporterConfig) metricsRequest(sr types.SignalReport) (*collectormetricsv1.ExportMetricsServiceRequest, error) {
+	var (
+		now      = uint64(cfg.now().UnixNano()) //nolint:gosec
+		resource = &resourcev1.Resource{
+			Attributes: stringAttributes(cfg.resourceAttributes),
+		}
+		scopes = make([]*metricsv1.ScopeMetrics, 0, len(sr.Report))
+	)
+
+	for _, name := range sr.Report.Names() {
+		var (
+			gauges []*metricsv1.Metric
+			attrs  []*commonv1.KeyValue
+		)
+		err := walk(sr.Report[name], "", func(key string, v any, t types.ValueType) error {
+			if m, ok := gauge(key, v, t); ok {
+				gauges = append(gauges, m)
+				return nil
+			}
+			a, err := attribute(key, v, t)
+			if err != nil {
+				return err
+			}
+			attrs = append(attrs, a)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("workflow %s: %w", name, err)
+		}
+
+		if cfg.stringsAsResourceAttributes {
+			for _, a := range attrs {
+				a.Key = name + "." + a.Key
+			}
+			resource.Attributes = append(resource.Attributes, attrs...)
+			attrs = nil
+		}
+		attrs = append(attrs, &commonv1.KeyValue{
+			Key:   SignalAttributeKey,
+			Value: stringValue(string(sr.Signal)),
+		})
+
+		for _, g := range gauges {
+			for _, dp := range g.GetGauge().GetDataPoints() {
+				dp.TimeUnixNano = now
+				dp.Attributes = attrs
+			}
+		}
+		scopes = append(scopes, &metricsv1.ScopeMetrics{
+			Scope: &commonv1.InstrumentationScope{
+				Name: name,
+			},
+			Metrics: gauges,
+		})
+	}
+
+	return &collectormetricsv1.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricsv1.ResourceMetrics{
+			{
+				Resource:     resource,
+				ScopeMetrics: scopes,
+			},
+		},
+	}, nil
+}
+
+// walk calls f for every value in the report, descending into nested reports,
+// in key order.
+func walk(report types.ProviderReport, prefix string, f func(string, any, types.ValueType) error) error {
+	for _, k := range report.Keys() {
+		key := string(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		v, t, err := types.CanonicalValue(report[k])
+		if err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+		if t == types.ValueTypeReport {
+			if err := walk(v.(types.ProviderReport), key, f); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := f(key, v, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gauge returns a gauge with a single data point for numeric values.
+func gauge(key string, v any, t types.ValueType) (*metricsv1.Metric, bool) {
+	var (
+		dp   = &metricsv1.NumberDataPoint{}
+		unit string
+	)
+	switch t { //nolint:exhaustive
+	case types.ValueTypeInt:
+		dp.Value = &metricsv1.NumberDataPoint_AsInt{AsInt: v.(int64)}
+	case types.ValueTypeFloat:
+		dp.Value = &metricsv1.NumberDataPoint_AsDouble{AsDouble: v.(float64)}
+	case types.ValueTypeDuration:
+		dp.Value = &metricsv1.NumberDataPoint_AsDouble{AsDouble: v.(time.Duration).Seconds()}
+		unit = durationUnit
+	case types.ValueTypeBool:
+		var i int64
+		if v.(bool) {
+			i = 1
+		}
+		dp.Value = &metricsv1.NumberDataPoint_AsInt{AsInt: i}
+	default:
+		return nil, false
+	}
+
+	return &metricsv1.Metric{
+		Name: key,
+		Unit: unit,
+		Data: &metricsv1.Metric_Gauge{
+			Gauge: &metricsv1.Gauge{
+				DataPoints: []*metricsv1.NumberDataPoint{dp},
+			},
+		},
+	}, true
+}
+
+// attribute returns an attribute for non numeric values.
+func attribute(key string, v any, t types.ValueType) (*commonv1.KeyValue, error) {
+	kv := &commonv1.KeyValue{Key: key}
+	switch t { //nolint:exhaustive
+	case types.ValueTypeString:
+		kv.Value = stringValue(v.(string))
+	case types.ValueTypeTime:
+		kv.Value = stringValue(v.(time.Time).UTC().Format(time.RFC3339Nano))
+	case types.ValueTypeStringList:
+		l := v.([]string)
+		values := make([]*commonv1.AnyValue, 0, len(l))
+		for _, s := range l {
+			values = append(values, stringValue(s))
+		}
+		kv.Value = &commonv1.AnyValue{
+			Value: &commonv1.AnyValue_ArrayValue{
+				ArrayValue: &commonv1.ArrayValue{Values: values},
+			},
+		}
+	default:
+		return nil, fmt.Errorf("key %s: cannot map value of type %s onto an attribute", key, t)
+	}
+	return kv, nil
+}
+
+func stringValue(s string) *commonv1.AnyValue {
+	return &commonv1.AnyValue{
+		Value: &commonv1.AnyValue_StringValue{StringValue: s},
+	}
+}
+
+func stringAttributes(m map[string]string) []*commonv1.KeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]*commonv1.KeyValue, 0, len(m))
+	for _, k := range keys {
+		out = append(out, &commonv1.KeyValue{Key: k, Value: stringValue(m[k])})
+	}
+	return out
+}
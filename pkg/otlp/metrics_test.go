@@ -0,0 +1,126 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func testSignalReport() types.SignalReport {
+	return types.SignalReport{
+		Signal: "kic-ping",
+		Report: types.Report{
+			"identify-platform": types.ProviderReport{
+				"k8sv":           "v1.27.1",
+				"k8s_pods_count": 7,
+			},
+			"state": types.ProviderReport{
+				"uptime": 90 * time.Second,
+				"ratio":  0.5,
+				"ok":     true,
+				"l":      []string{"a", "b"},
+				"nested": types.ProviderReport{
+					"n": uint8(3),
+				},
+			},
+		},
+	}
+}
+
+func TestNewMetricsRequest(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	withNow := func(c *exporterConfig) {
+		c.now = func() time.Time { return now }
+	}
+
+	t.Run("data point attributes", func(t *testing.T) {
+		req, err := NewMetricsRequest(testSignalReport(), withNow,
+			OptExporterResourceAttributes(map[string]string{"service.name": "kic"}),
+		)
+		require.NoError(t, err)
+		require.Len(t, req.GetResourceMetrics(), 1)
+		rm := req.GetResourceMetrics()[0]
+		assert.Equal(t, map[string]any{"service.name": "kic"}, attributesMap(rm.GetResource().GetAttributes()))
+
+		scopes := rm.GetScopeMetrics()
+		require.Len(t, scopes, 2)
+		assert.Equal(t, "identify-platform", scopes[0].GetScope().GetName())
+		assert.Equal(t, "state", scopes[1].GetScope().GetName())
+
+		assert.Equal(t, map[string]any{"k8s_pods_count": int64(7)}, gaugesMap(scopes[0].GetMetrics()))
+		assert.Equal(t, map[string]any{
+			"nested.n": int64(3),
+			"ok":       int64(1),
+			"ratio":    0.5,
+			"uptime":   90.0,
+		}, gaugesMap(scopes[1].GetMetrics()))
+		assert.Equal(t, "s", scopes[1].GetMetrics()[3].GetUnit())
+
+		dp := scopes[0].GetMetrics()[0].GetGauge().GetDataPoints()[0]
+		assert.Equal(t, uint64(now.UnixNano()), dp.GetTimeUnixNano()) //nolint:gosec
+		assert.Equal(t, map[string]any{"k8sv": "v1.27.1", "signal": "kic-ping"}, attributesMap(dp.GetAttributes()))
+
+		dp = scopes[1].GetMetrics()[0].GetGauge().GetDataPoints()[0]
+		assert.Equal(t, map[string]any{"l": []any{"a", "b"}, "signal": "kic-ping"}, attributesMap(dp.GetAttributes()))
+	})
+
+	t.Run("resource attributes", func(t *testing.T) {
+		req, err := NewMetricsRequest(testSignalReport(), withNow, OptExporterStringsAsResourceAttributes())
+		require.NoError(t, err)
+		rm := req.GetResourceMetrics()[0]
+		assert.Equal(t, map[string]any{
+			"identify-platform.k8sv": "v1.27.1",
+			"state.l":                []any{"a", "b"},
+		}, attributesMap(rm.GetResource().GetAttributes()))
+
+		dp := rm.GetScopeMetrics()[0].GetMetrics()[0].GetGauge().GetDataPoints()[0]
+		assert.Equal(t, map[string]any{"signal": "kic-ping"}, attributesMap(dp.GetAttributes()))
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		_, err := NewMetricsRequest(types.SignalReport{
+			Report: types.Report{"w": types.ProviderReport{"k": struct{}{}}},
+		})
+		var unsupported types.ErrUnsupportedValue
+		require.ErrorAs(t, err, &unsupported)
+	})
+}
+
+func gaugesMap(metrics []*metricsv1.Metric) map[string]any {
+	out := map[string]any{}
+	for _, m := range metrics {
+		dp := m.GetGauge().GetDataPoints()[0]
+		switch v := dp.GetValue().(type) {
+		case *metricsv1.NumberDataPoint_AsInt:
+			out[m.GetName()] = v.AsInt
+		case *metricsv1.NumberDataPoint_AsDouble:
+			out[m.GetName()] = v.AsDouble
+		}
+	}
+	return out
+}
+
+func attributesMap(attrs []*commonv1.KeyValue) map[string]any {
+	out := map[string]any{}
+	for _, a := range attrs {
+		out[a.GetKey()] = anyValue(a.GetValue())
+	}
+	return out
+}
+
+func anyValue(v *commonv1.AnyValue) any {
+	if l := v.GetArrayValue(); l != nil {
+		out := make([]any, 0, len(l.GetValues()))
+		for _, e := range l.GetValues() {
+			out = append(out, anyValue(e))
+		}
+		return out
+	}
+	return v.GetStringValue()
+}
@@ -0,0 +1,87 @@
+package otlp
+
+import (
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultTimeout = time.Second * 30
+)
+
+type exporterConfig struct {
+	headers                     map[string]string
+	resourceAttributes          map[string]string
+	stringsAsResourceAttributes bool
+	timeout                     time.Duration
+	httpClient                  *http.Client
+	grpcDialOptions             []grpc.DialOption
+	now                         func() time.Time
+}
+
+// OptExporter is the option function type that can configure OTLP exporters.
+type OptExporter func(*exporterConfig)
+
+// OptExporterHeaders returns an option that sets headers sent with every export
+// request: HTTP headers for the HTTP exporter and metadata for the gRPC exporter.
+// They can be used e.g. for authentication.
+func OptExporterHeaders(headers map[string]string) OptExporter {
+	return func(c *exporterConfig) {
+		c.headers = headers
+	}
+}
+
+// OptExporterResourceAttributes returns an option that sets attributes of the
+// resource all exported metrics belong to, e.g. "service.name".
+func OptExporterResourceAttributes(attributes map[string]string) OptExporter {
+	return func(c *exporterConfig) {
+		c.resourceAttributes = attributes
+	}
+}
+
+// OptExporterStringsAsResourceAttributes returns an option that maps non numeric
+// report values onto resource attributes instead of data point attributes.
+func OptExporterStringsAsResourceAttributes() OptExporter {
+	return func(c *exporterConfig) {
+		c.stringsAsResourceAttributes = true
+	}
+}
+
+// OptExporterTimeout returns an option that sets the timeout of export requests
+// which is used when the context passed to Forward has no deadline.
+func OptExporterTimeout(timeout time.Duration) OptExporter {
+	return func(c *exporterConfig) {
+		c.timeout = timeout
+	}
+}
+
+// OptExporterHTTPClient returns an option that sets the HTTP client used by
+// the HTTP exporter, e.g. to configure TLS. It has no effect on the gRPC exporter.
+func OptExporterHTTPClient(client *http.Client) OptExporter {
+	return func(c *exporterConfig) {
+		c.httpClient = client
+	}
+}
+
+// OptExporterGRPCDialOptions returns an option that adds dial options used by
+// the gRPC exporter, e.g. to configure transport credentials. It has no effect
+// on the HTTP exporter.
+func OptExporterGRPCDialOptions(opts ...grpc.DialOption) OptExporter {
+	return func(c *exporterConfig) {
+		c.grpcDialOptions = append(c.grpcDialOptions, opts...)
+	}
+}
+
+func newExporterConfig(opts []OptExporter) exporterConfig {
+	cfg := exporterConfig{
+		timeout:    defaultTimeout,
+		httpClient: http.DefaultClient,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
@@ -16,3 +16,22 @@ type ErrGVRNotAvailable struct {
 func (e ErrGVRNotAvailable) Error() string {
 	return fmt.Sprintf("GVR %q not available, reason: %v", e.GVR, e.Reason)
 }
+
+type providerErr string
+
+func (e providerErr) Error() string {
+	return string(e)
+}
+
+// ErrEmptyKind occurs when a descriptor with an empty kind is registered.
+const ErrEmptyKind = providerErr("provider kind cannot be empty")
+
+// ErrKindAlreadyRegistered is an error which indicates that a descriptor for
+// the given provider kind has already been registered.
+type ErrKindAlreadyRegistered struct {
+	Kind Kind
+}
+
+func (e ErrKindAlreadyRegistered) Error() string {
+	return fmt.Sprintf("provider kind %q already registered", e.Kind)
+}
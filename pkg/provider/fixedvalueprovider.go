@@ -13,6 +13,16 @@ type fixedValue struct {
 
 var _ Provider = (*fixedValue)(nil)
 
+// FixedValueKind represents fixed value provider kind.
+const FixedValueKind = Kind("fixed-value")
+
+func init() {
+	mustRegister(Descriptor{
+		Kind:        FixedValueKind,
+		Description: "Reports a fixed, user defined set of values.",
+	})
+}
+
 // NewFixedValueProvider creates fixed value provider which upon calling Provide
 // will always provide the same telemetry report.
 func NewFixedValueProvider(name string, data types.ProviderReport) (Provider, error) {
@@ -20,7 +30,7 @@ func NewFixedValueProvider(name string, data types.ProviderReport) (Provider, er
 		data: data,
 		base: base{
 			name: name,
-			kind: "fixed-value",
+			kind: FixedValueKind,
 		},
 	}, nil
 }
@@ -17,6 +17,16 @@ type ReportFunctor func(context.Context) (types.ProviderReport, error)
 
 var _ Provider = (*functor)(nil)
 
+// FunctorKind represents functor provider kind.
+const FunctorKind = Kind("functor")
+
+func init() {
+	mustRegister(Descriptor{
+		Kind:        FunctorKind,
+		Description: "Reports values returned by a user defined function.",
+	})
+}
+
 // NewFunctorProvider creates a new functor provider that allows to define one's
 // own telemetry retrieval logic by providing a ReportFunctor as parameter.
 func NewFunctorProvider(name string, f ReportFunctor) (Provider, error) {
@@ -24,7 +34,7 @@ func NewFunctorProvider(name string, f ReportFunctor) (Provider, error) {
 		f: f,
 		base: base{
 			name: name,
-			kind: "functor",
+			kind: FunctorKind,
 		},
 	}, nil
 }
@@ -10,8 +10,20 @@ import (
 const (
 	// HostnameKey is the report key that under which one can find hostname.
 	HostnameKey = types.ProviderReportKey("hn")
+	// HostnameKind represents hostname provider kind.
+	HostnameKind = Kind("hostname")
 )
 
+func init() {
+	mustRegister(Descriptor{
+		Kind:        HostnameKind,
+		Description: "Reports the hostname of the machine this framework is running on.",
+		Keys: []KeyDescriptor{
+			{Key: HostnameKey, Type: types.ValueTypeString, Description: "Hostname as reported by the kernel."},
+		},
+	})
+}
+
 // NewHostnameProvider creates hostname provider.
 func NewHostnameProvider(name string) (Provider, error) {
 	return &functor{
@@ -26,7 +38,7 @@ func NewHostnameProvider(name string) (Provider, error) {
 		},
 		base: base{
 			name: name,
-			kind: "hostname",
+			kind: HostnameKind,
 		},
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -18,6 +19,20 @@ const (
 	ClusterProviderKind = Kind(ClusterProviderKey)
 )
 
+func init() {
+	mustRegister(Descriptor{
+		Kind:        ClusterProviderKind,
+		Description: "Reports the cluster provider inferred from the cluster version and nodes.",
+		Keys: []KeyDescriptor{
+			{Key: ClusterProviderKey, Type: types.ValueTypeString, Description: "Cluster provider, e.g. GKE, AWS or UNKNOWN."},
+		},
+		RBAC: []rbacv1.PolicyRule{
+			{NonResourceURLs: []string{"/version"}, Verbs: []string{"get"}},
+			{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list"}},
+		},
+	})
+}
+
 // ClusterProvider identifies a particular clsuter provider like AWS, GKE, Azure etc.
 type ClusterProvider string
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
 
@@ -17,6 +18,19 @@ const (
 	ClusterArchKind = Kind(ClusterArchKey)
 )
 
+func init() {
+	mustRegister(Descriptor{
+		Kind:        ClusterArchKind,
+		Description: "Reports the cluster architecture as returned by the /version API.",
+		Keys: []KeyDescriptor{
+			{Key: ClusterArchKey, Type: types.ValueTypeString, Description: "Cluster architecture, e.g. linux/amd64."},
+		},
+		RBAC: []rbacv1.PolicyRule{
+			{NonResourceURLs: []string{"/version"}, Verbs: []string{"get"}},
+		},
+	})
+}
+
 // NewK8sClusterArchProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get cluster architecture.
 func NewK8sClusterArchProvider(name string, kc kubernetes.Interface) (Provider, error) {
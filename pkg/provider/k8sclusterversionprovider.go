@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	utilversion "k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
@@ -23,6 +24,20 @@ const (
 	ClusterVersionKind = Kind(ClusterVersionKey)
 )
 
+func init() {
+	mustRegister(Descriptor{
+		Kind:        ClusterVersionKind,
+		Description: "Reports the cluster Kubernetes version as returned by the /version API.",
+		Keys: []KeyDescriptor{
+			{Key: ClusterVersionKey, Type: types.ValueTypeString, Description: "Cluster git version, e.g. v1.24.1-gke.1400."},
+			{Key: ClusterVersionSemverKey, Type: types.ValueTypeString, Description: "Cluster version in semver format, e.g. v1.24.1."},
+		},
+		RBAC: []rbacv1.PolicyRule{
+			{NonResourceURLs: []string{"/version"}, Verbs: []string{"get"}},
+		},
+	})
+}
+
 // NewK8sClusterVersionProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get cluster k8s version.
 func NewK8sClusterVersionProvider(name string, kc kubernetes.Interface) (Provider, error) {
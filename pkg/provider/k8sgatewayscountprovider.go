@@ -16,14 +16,19 @@ const (
 	GatewayCountKind = Kind(GatewayCountKey)
 )
 
+var gatewaysGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "gateways",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(GatewayCountKind, GatewayCountKey, gatewaysGVR))
+}
+
 // NewK8sGatewayCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a gateway count from
 // the cluster.
 func NewK8sGatewayCountProvider(name string, m metadata.Interface, rm meta.RESTMapper) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1",
-		Resource: "gateways",
-	}
-	return NewK8sObjectCountProviderWithRESTMapper(name, GatewayCountKind, m, gvr, rm)
+	return NewK8sObjectCountProviderWithRESTMapper(name, GatewayCountKind, m, gatewaysGVR, rm)
 }
@@ -16,14 +16,19 @@ const (
 	GRPCRouteCountKind = Kind(GRPCRouteCountKey)
 )
 
+var grpcroutesGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "grpcroutes",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(GRPCRouteCountKind, GRPCRouteCountKey, grpcroutesGVR))
+}
+
 // NewK8sGRPCRouteCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a GRPCRoute count from
 // the cluster.
 func NewK8sGRPCRouteCountProvider(name string, m metadata.Interface, rm meta.RESTMapper) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1",
-		Resource: "grpcroutes",
-	}
-	return NewK8sObjectCountProviderWithRESTMapper(name, GRPCRouteCountKind, m, gvr, rm)
+	return NewK8sObjectCountProviderWithRESTMapper(name, GRPCRouteCountKind, m, grpcroutesGVR, rm)
 }
@@ -16,14 +16,19 @@ const (
 	HTTPRouteCountKind = Kind(HTTPRouteCountKey)
 )
 
+var httproutesGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "httproutes",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(HTTPRouteCountKind, HTTPRouteCountKey, httproutesGVR))
+}
+
 // NewK8sHTTPRouteCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a HTTPRoute count from
 // the cluster.
 func NewK8sHTTPRouteCountProvider(name string, m metadata.Interface, rm meta.RESTMapper) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1",
-		Resource: "httproutes",
-	}
-	return NewK8sObjectCountProviderWithRESTMapper(name, HTTPRouteCountKind, m, gvr, rm)
+	return NewK8sObjectCountProviderWithRESTMapper(name, HTTPRouteCountKind, m, httproutesGVR, rm)
 }
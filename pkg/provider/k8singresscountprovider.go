@@ -15,15 +15,19 @@ const (
 	IngressCountKind = Kind(IngressCountKey)
 )
 
+var ingressesGVR = schema.GroupVersionResource{
+	Group:    "networking.k8s.io",
+	Version:  "v1",
+	Resource: "ingresses",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(IngressCountKind, IngressCountKey, ingressesGVR))
+}
+
 // NewK8sIngressCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a pod count from
 // the cluster.
 func NewK8sIngressCountProvider(name string, m metadata.Interface) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "networking.k8s.io",
-		Version:  "v1",
-		Resource: "ingresses",
-	}
-
-	return NewK8sObjectCountProvider(name, IngressCountKind, m, gvr)
+	return NewK8sObjectCountProvider(name, IngressCountKind, m, ingressesGVR)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	rbacv1 "k8s.io/api/rbac/v1"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -17,8 +18,34 @@ const (
 	MeshDetectProviderKey = types.ProviderReportKey("mesh_detect")
 	// MeshDetectKind represents the mesh detect provider kind.
 	MeshDetectKind = Kind("mesh_detect")
+
+	// MeshDeploymentKey is report key under which meshes deployed in the cluster
+	// are reported.
+	MeshDeploymentKey = meshdetect.DeploymentReportKey
+	// MeshRunUnderKey is report key under which meshes that the pod runs under
+	// are reported.
+	MeshRunUnderKey = meshdetect.RunUnderReportKey
+	// MeshServiceDistributionKey is report key under which the distribution of
+	// services across meshes is reported.
+	MeshServiceDistributionKey = meshdetect.ServiceDistributionReportKey
 )
 
+func init() {
+	mustRegister(Descriptor{
+		Kind:        MeshDetectKind,
+		Description: "Reports service meshes detected in the cluster.",
+		Keys: []KeyDescriptor{
+			{Key: MeshDeploymentKey, Type: types.ValueTypeString, Description: "Meshes deployed in the cluster, e.g. a3,c3."},
+			{Key: MeshRunUnderKey, Type: types.ValueTypeString, Description: "Meshes that the pod is running under, e.g. i1,i2,i3."},
+			{Key: MeshServiceDistributionKey, Type: types.ValueTypeString, Description: "Number of all services and services per mesh, e.g. all8,a2,c2."},
+		},
+		RBAC: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"pods", "services"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{"discovery.k8s.io"}, Resources: []string{"endpointslices"}, Verbs: []string{"list"}},
+		},
+	})
+}
+
 // NewMeshDetectProvider returns a mesh detection provider, which will provide
 // a information about detected meshes in kubernetes cluster.
 func NewMeshDetectProvider(name string, cl client.Client, pod, publishService apitypes.NamespacedName) (Provider, error) {
@@ -15,15 +15,19 @@ const (
 	NodeCountKind = Kind(NodeCountKey)
 )
 
+var nodesGVR = schema.GroupVersionResource{
+	Group:    "",
+	Version:  "v1",
+	Resource: "nodes",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(NodeCountKind, NodeCountKey, nodesGVR))
+}
+
 // NewK8sNodeCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a node count from
 // the cluster.
 func NewK8sNodeCountProvider(name string, m metadata.Interface) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "",
-		Version:  "v1",
-		Resource: "nodes",
-	}
-
-	return NewK8sObjectCountProvider(name, NodeCountKind, m, gvr)
+	return NewK8sObjectCountProvider(name, NodeCountKind, m, nodesGVR)
 }
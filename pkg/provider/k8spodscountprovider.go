@@ -15,15 +15,19 @@ const (
 	PodCountKind = Kind(PodCountKey)
 )
 
+var podsGVR = schema.GroupVersionResource{
+	Group:    "",
+	Version:  "v1",
+	Resource: "pods",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(PodCountKind, PodCountKey, podsGVR))
+}
+
 // NewK8sPodCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a pod count from
 // the cluster.
 func NewK8sPodCountProvider(name string, m metadata.Interface) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "",
-		Version:  "v1",
-		Resource: "pods",
-	}
-
-	return NewK8sObjectCountProvider(name, PodCountKind, m, gvr)
+	return NewK8sObjectCountProvider(name, PodCountKind, m, podsGVR)
 }
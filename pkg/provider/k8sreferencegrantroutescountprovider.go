@@ -16,14 +16,19 @@ const (
 	ReferenceGrantCountKind = Kind(ReferenceGrantCountKey)
 )
 
+var referencegrantsGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "referencegrants",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(ReferenceGrantCountKind, ReferenceGrantCountKey, referencegrantsGVR))
+}
+
 // NewK8sReferenceGrantCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a ReferenceGrant count from
 // the cluster.
 func NewK8sReferenceGrantCountProvider(name string, m metadata.Interface, rm meta.RESTMapper) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1",
-		Resource: "referencegrants",
-	}
-	return NewK8sObjectCountProviderWithRESTMapper(name, ReferenceGrantCountKind, m, gvr, rm)
+	return NewK8sObjectCountProviderWithRESTMapper(name, ReferenceGrantCountKind, m, referencegrantsGVR, rm)
 }
@@ -15,15 +15,19 @@ const (
 	ServiceCountKind = Kind(ServiceCountKey)
 )
 
+var servicesGVR = schema.GroupVersionResource{
+	Group:    "",
+	Version:  "v1",
+	Resource: "services",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(ServiceCountKind, ServiceCountKey, servicesGVR))
+}
+
 // NewK8sServiceCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a service count from
 // the cluster.
 func NewK8sServiceCountProvider(name string, m metadata.Interface) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "",
-		Version:  "v1",
-		Resource: "services",
-	}
-
-	return NewK8sObjectCountProvider(name, ServiceCountKind, m, gvr)
+	return NewK8sObjectCountProvider(name, ServiceCountKind, m, servicesGVR)
 }
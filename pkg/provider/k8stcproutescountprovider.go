@@ -16,14 +16,19 @@ const (
 	TCPRouteCountKind = Kind(TCPRouteCountKey)
 )
 
+var tcproutesGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1alpha2",
+	Resource: "tcproutes",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(TCPRouteCountKind, TCPRouteCountKey, tcproutesGVR))
+}
+
 // NewK8sTCPRouteCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a TCPRoute count from
 // the cluster.
 func NewK8sTCPRouteCountProvider(name string, m metadata.Interface, rm meta.RESTMapper) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1alpha2",
-		Resource: "tcproutes",
-	}
-	return NewK8sObjectCountProviderWithRESTMapper(name, TCPRouteCountKind, m, gvr, rm)
+	return NewK8sObjectCountProviderWithRESTMapper(name, TCPRouteCountKind, m, tcproutesGVR, rm)
 }
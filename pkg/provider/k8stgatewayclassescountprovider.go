@@ -16,14 +16,19 @@ const (
 	GatewayClassCountKind = Kind(GatewayClassCountKey)
 )
 
+var gatewayclassesGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1",
+	Resource: "gatewayclasses",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(GatewayClassCountKind, GatewayClassCountKey, gatewayclassesGVR))
+}
+
 // NewK8sGatewayClassCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a GatewayClass count from
 // the cluster.
 func NewK8sGatewayClassCountProvider(name string, m metadata.Interface, rm meta.RESTMapper) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1",
-		Resource: "gatewayclasses",
-	}
-	return NewK8sObjectCountProviderWithRESTMapper(name, GatewayClassCountKind, m, gvr, rm)
+	return NewK8sObjectCountProviderWithRESTMapper(name, GatewayClassCountKind, m, gatewayclassesGVR, rm)
 }
@@ -16,14 +16,19 @@ const (
 	TLSRouteCountKind = Kind(TLSRouteCountKey)
 )
 
+var tlsroutesGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1alpha2",
+	Resource: "tlsroutes",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(TLSRouteCountKind, TLSRouteCountKey, tlsroutesGVR))
+}
+
 // NewK8sTLSRouteCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a TLSRoute count from
 // the cluster.
 func NewK8sTLSRouteCountProvider(name string, m metadata.Interface, rm meta.RESTMapper) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1alpha2",
-		Resource: "tlsroutes",
-	}
-	return NewK8sObjectCountProviderWithRESTMapper(name, TLSRouteCountKind, m, gvr, rm)
+	return NewK8sObjectCountProviderWithRESTMapper(name, TLSRouteCountKind, m, tlsroutesGVR, rm)
 }
@@ -16,14 +16,19 @@ const (
 	UDPRouteCountKind = Kind(UDPRouteCountKey)
 )
 
+var udproutesGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1alpha2",
+	Resource: "udproutes",
+}
+
+func init() {
+	mustRegister(objectCountDescriptor(UDPRouteCountKind, UDPRouteCountKey, udproutesGVR))
+}
+
 // NewK8sUDPRouteCountProvider creates telemetry data provider that will query the
 // configured k8s cluster - using the provided client - to get a UDPRoute count from
 // the cluster.
 func NewK8sUDPRouteCountProvider(name string, m metadata.Interface, rm meta.RESTMapper) (Provider, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1alpha2",
-		Resource: "udproutes",
-	}
-	return NewK8sObjectCountProviderWithRESTMapper(name, UDPRouteCountKind, m, gvr, rm)
+	return NewK8sObjectCountProviderWithRESTMapper(name, UDPRouteCountKind, m, udproutesGVR, rm)
 }
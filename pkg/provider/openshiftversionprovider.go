@@ -5,6 +5,7 @@ import (
 
 	"github.com/blang/semver/v4"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -29,6 +30,19 @@ const (
 	ImageVersionVariable = "OPERATOR_IMAGE_VERSION"
 )
 
+func init() {
+	mustRegister(Descriptor{
+		Kind:        OpenShiftVersionKind,
+		Description: "Reports the OpenShift version, if the cluster is an OpenShift cluster.",
+		Keys: []KeyDescriptor{
+			{Key: OpenShiftVersionKey, Type: types.ValueTypeString, Description: "OpenShift version, e.g. 4.13.0."},
+		},
+		RBAC: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"list"}},
+		},
+	})
+}
+
 // NewOpenShiftVersionProvider provides the OpenShift version, or nothing if the cluster is not OpenShift.
 func NewOpenShiftVersionProvider(name string, kc kubernetes.Interface) (Provider, error) {
 	return NewK8sClientGoBase(name, OpenShiftVersionKind, kc, openShiftVersionReport)
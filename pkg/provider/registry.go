@@ -0,0 +1,239 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// KeyDescriptor describes a single key that a provider emits in its report.
+type KeyDescriptor struct {
+	// Key is the report key.
+	Key types.ProviderReportKey `json:"key"`
+	// Type is the type of the value reported under Key.
+	Type types.ValueType `json:"type"`
+	// Description describes the reported value.
+	Description string `json:"description"`
+}
+
+// Descriptor describes a provider kind: what it reports, under which keys and
+// what RBAC permissions it needs in order to do so.
+type Descriptor struct {
+	// Kind is the provider kind as returned by Provider's Kind().
+	Kind Kind `json:"kind"`
+	// Description is a human readable description of the provider.
+	Description string `json:"description"`
+	// Keys lists keys that the provider emits. It's empty for providers which
+	// keys are defined by the user, like fixed value or functor providers.
+	Keys []KeyDescriptor `json:"keys,omitempty"`
+	// RBAC lists the rules which are required for the provider to work.
+	RBAC []rbacv1.PolicyRule `json:"rbac,omitempty"`
+}
+
+// Registry is a catalogue of provider descriptors.
+type Registry struct {
+	lock        sync.RWMutex
+	descriptors map[Kind]Descriptor
+}
+
+// NewRegistry creates a new, empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		descriptors: make(map[Kind]Descriptor),
+	}
+}
+
+// Register adds the descriptor to the registry. It returns an error when the
+// descriptor has an empty kind, when it repeats a key or when a descriptor for
+// the same kind has already been registered.
+func (r *Registry) Register(d Descriptor) error {
+	if d.Kind == "" {
+		return ErrEmptyKind
+	}
+
+	keys := make(map[types.ProviderReportKey]struct{}, len(d.Keys))
+	for _, k := range d.Keys {
+		if _, ok := keys[k.Key]; ok {
+			return fmt.Errorf("%s: duplicate key %q", d.Kind, k.Key)
+		}
+		keys[k.Key] = struct{}{}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.descriptors[d.Kind]; ok {
+		return ErrKindAlreadyRegistered{Kind: d.Kind}
+	}
+	r.descriptors[d.Kind] = d
+	return nil
+}
+
+// Lookup returns the descriptor registered for the provided kind.
+func (r *Registry) Lookup(kind Kind) (Descriptor, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	d, ok := r.descriptors[kind]
+	return d, ok
+}
+
+// Descriptors returns all registered descriptors sorted by kind.
+func (r *Registry) Descriptors() []Descriptor {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	out := make([]Descriptor, 0, len(r.descriptors))
+	for _, d := range r.descriptors {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Kind < out[j].Kind
+	})
+	return out
+}
+
+// PolicyRules returns an aggregated list of RBAC rules required by providers
+// of the requested kinds. When no kinds are provided, rules for all registered
+// providers are returned.
+//
+// Rules are merged per API group and resource (or per non resource URL) so that
+// the result can be used directly in a ClusterRole manifest.
+func (r *Registry) PolicyRules(kinds ...Kind) []rbacv1.PolicyRule {
+	var descriptors []Descriptor
+	if len(kinds) == 0 {
+		descriptors = r.Descriptors()
+	} else {
+		for _, k := range kinds {
+			if d, ok := r.Lookup(k); ok {
+				descriptors = append(descriptors, d)
+			}
+		}
+	}
+
+	var (
+		resources    = make(map[schema.GroupResource]map[string]struct{})
+		nonResources = make(map[string]map[string]struct{})
+		addVerbs     = func(m map[string]struct{}, verbs []string) {
+			for _, v := range verbs {
+				m[v] = struct{}{}
+			}
+		}
+	)
+	for _, d := range descriptors {
+		for _, rule := range d.RBAC {
+			for _, g := range rule.APIGroups {
+				for _, res := range rule.Resources {
+					gr := schema.GroupResource{Group: g, Resource: res}
+					if resources[gr] == nil {
+						resources[gr] = make(map[string]struct{})
+					}
+					addVerbs(resources[gr], rule.Verbs)
+				}
+			}
+			for _, u := range rule.NonResourceURLs {
+				if nonResources[u] == nil {
+					nonResources[u] = make(map[string]struct{})
+				}
+				addVerbs(nonResources[u], rule.Verbs)
+			}
+		}
+	}
+
+	out := make([]rbacv1.PolicyRule, 0, len(resources)+len(nonResources))
+	for gr, verbs := range resources {
+		out = append(out, rbacv1.PolicyRule{
+			APIGroups: []string{gr.Group},
+			Resources: []string{gr.Resource},
+			Verbs:     sortedSet(verbs),
+		})
+	}
+	for u, verbs := range nonResources {
+		out = append(out, rbacv1.PolicyRule{
+			NonResourceURLs: []string{u},
+			Verbs:           sortedSet(verbs),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return ruleSortKey(out[i]) < ruleSortKey(out[j])
+	})
+	return out
+}
+
+func sortedSet(m map[string]struct{}) []string {
+	out := make([]string, 0, len(m))
+	for v := range m {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func ruleSortKey(r rbacv1.PolicyRule) string {
+	return strings.Join(r.APIGroups, ",") + "/" +
+		strings.Join(r.Resources, ",") + "/" +
+		strings.Join(r.NonResourceURLs, ",")
+}
+
+// defaultRegistry holds descriptors of all providers defined in this package.
+var defaultRegistry = NewRegistry()
+
+// Register registers the descriptor in the default registry. It can be used
+// to make custom providers discoverable next to the builtin ones.
+func Register(d Descriptor) error {
+	return defaultRegistry.Register(d)
+}
+
+// Lookup returns the descriptor registered for the provided kind in the default
+// registry.
+func Lookup(kind Kind) (Descriptor, bool) {
+	return defaultRegistry.Lookup(kind)
+}
+
+// Descriptors returns all descriptors registered in the default registry,
+// sorted by kind.
+func Descriptors() []Descriptor {
+	return defaultRegistry.Descriptors()
+}
+
+// PolicyRules returns the aggregated RBAC rules required by the requested
+// provider kinds, as registered in the default registry.
+func PolicyRules(kinds ...Kind) []rbacv1.PolicyRule {
+	return defaultRegistry.PolicyRules(kinds...)
+}
+
+// mustRegister registers the descriptor in the default registry and panics
+// on error. It's meant to be used for builtin providers only.
+func mustRegister(d Descriptor) {
+	if err := defaultRegistry.Register(d); err != nil {
+		panic("failed to register provider descriptor: " + err.Error())
+	}
+}
+
+// objectCountDescriptor returns a descriptor for object count providers.
+func objectCountDescriptor(kind Kind, key types.ProviderReportKey, gvr schema.GroupVersionResource) Descriptor {
+	return Descriptor{
+		Kind:        kind,
+		Description: fmt.Sprintf("Counts %s in the cluster.", gvr.GroupResource()),
+		Keys: []KeyDescriptor{
+			{
+				Key:         key,
+				Type:        types.ValueTypeInt,
+				Description: fmt.Sprintf("Number of %s in the cluster.", gvr.Resource),
+			},
+		},
+		RBAC: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{gvr.Group},
+				Resources: []string{gvr.Resource},
+				Verbs:     []string{"list"},
+			},
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("register and lookup", func(t *testing.T) {
+		r := NewRegistry()
+		d := Descriptor{
+			Kind: "custom",
+			Keys: []KeyDescriptor{
+				{Key: "custom_key", Type: types.ValueTypeString},
+			},
+		}
+		require.NoError(t, r.Register(d))
+
+		got, ok := r.Lookup("custom")
+		require.True(t, ok)
+		require.Equal(t, d, got)
+
+		_, ok = r.Lookup("other")
+		require.False(t, ok)
+	})
+
+	t.Run("invalid descriptors are rejected", func(t *testing.T) {
+		r := NewRegistry()
+		require.ErrorIs(t, r.Register(Descriptor{}), ErrEmptyKind)
+
+		require.Error(t, r.Register(Descriptor{
+			Kind: "custom",
+			Keys: []KeyDescriptor{
+				{Key: "custom_key", Type: types.ValueTypeString},
+				{Key: "custom_key", Type: types.ValueTypeInt},
+			},
+		}))
+
+		require.NoError(t, r.Register(Descriptor{Kind: "custom"}))
+		err := r.Register(Descriptor{Kind: "custom"})
+		require.ErrorAs(t, err, &ErrKindAlreadyRegistered{})
+	})
+
+	t.Run("policy rules are merged", func(t *testing.T) {
+		r := NewRegistry()
+		require.NoError(t, r.Register(Descriptor{
+			Kind: "a",
+			RBAC: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods", "services"}, Verbs: []string{"list"}},
+				{NonResourceURLs: []string{"/version"}, Verbs: []string{"get"}},
+			},
+		}))
+		require.NoError(t, r.Register(Descriptor{
+			Kind: "b",
+			RBAC: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+			},
+		}))
+
+		expected := []rbacv1.PolicyRule{
+			{NonResourceURLs: []string{"/version"}, Verbs: []string{"get"}},
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: []string{"list"}},
+		}
+		require.Equal(t, expected, r.PolicyRules())
+		require.Equal(t, expected, r.PolicyRules("a", "b", "unknown"))
+		require.Equal(t, []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list"}},
+		}, r.PolicyRules("b"))
+	})
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	for _, kind := range []Kind{
+		FixedValueKind,
+		FunctorKind,
+		HostnameKind,
+		UptimeKind,
+		ClusterArchKind,
+		ClusterVersionKind,
+		ClusterProviderKind,
+		OpenShiftVersionKind,
+		MeshDetectKind,
+		PodCountKind,
+		ServiceCountKind,
+		NodeCountKind,
+		IngressCountKind,
+		GatewayClassCountKind,
+		GatewayCountKind,
+		HTTPRouteCountKind,
+		GRPCRouteCountKind,
+		TLSRouteCountKind,
+		TCPRouteCountKind,
+		UDPRouteCountKind,
+		ReferenceGrantCountKind,
+	} {
+		_, ok := Lookup(kind)
+		require.Truef(t, ok, "builtin provider kind %q is not registered", kind)
+	}
+
+	d, ok := Lookup(GatewayCountKind)
+	require.True(t, ok)
+	require.Equal(t, []KeyDescriptor{
+		{Key: GatewayCountKey, Type: types.ValueTypeInt, Description: "Number of gateways in the cluster."},
+	}, d.Keys)
+	require.Equal(t, []rbacv1.PolicyRule{
+		{APIGroups: []string{"gateway.networking.k8s.io"}, Resources: []string{"gateways"}, Verbs: []string{"list"}},
+	}, d.RBAC)
+}
@@ -10,8 +10,20 @@ import (
 const (
 	// UptimeKey is the report key that under which one can find uptime.
 	UptimeKey = types.ProviderReportKey("uptime")
+	// UptimeKind represents uptime provider kind.
+	UptimeKind = Kind("uptime")
 )
 
+func init() {
+	mustRegister(Descriptor{
+		Kind:        UptimeKind,
+		Description: "Reports the time elapsed since the provider has been created.",
+		Keys: []KeyDescriptor{
+			{Key: UptimeKey, Type: types.ValueTypeInt, Description: "Uptime in seconds."},
+		},
+	})
+}
+
 // NewUptimeProvider provides new uptime provider which will return uptime counted
 // since the provider creation time.
 func NewUptimeProvider(name string) (Provider, error) {
@@ -24,7 +36,7 @@ func NewUptimeProvider(name string) (Provider, error) {
 		},
 		base: base{
 			name: name,
-			kind: "uptime",
+			kind: UptimeKind,
 		},
 	}, nil
 }
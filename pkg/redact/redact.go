@@ -0,0 +1,313 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+const (
+	// RedactedKeysKey is the key added to every workflow report that had any of
+	// its keys redacted. It holds a sorted list of "<key>=<action>" entries so
+	// that auditors can check what has been redacted and how.
+	RedactedKeysKey = types.ProviderReportKey("_redacted")
+
+	// SaltLength is the length of salts generated by NewSalt.
+	SaltLength = 32
+)
+
+// Action defines what happens to a value matched by a Rule.
+type Action string
+
+const (
+	// ActionDrop removes the key from the report.
+	ActionDrop = Action("drop")
+	// ActionHash replaces the value with a hex encoded HMAC-SHA256 of the value
+	// keyed with the redactor's salt. String lists have each element hashed.
+	// Values are hashed formatted like serializers format them, e.g. times in
+	// RFC 3339 in UTC, so that equal values hash the same.
+	ActionHash = Action("hash")
+	// ActionTruncate truncates string values (or each element of string lists)
+	// to Rule's Length runes.
+	ActionTruncate = Action("truncate")
+	// ActionBucketize replaces numeric values with a label of the bucket they
+	// fall into, as defined by Rule's Buckets.
+	ActionBucketize = Action("bucketize")
+)
+
+// Rule defines how values under matching keys should be redacted.
+//
+// Keys of nested reports are matched using their full path, i.e. keys of all
+// enclosing reports joined with a dot.
+type Rule struct {
+	// Workflow restricts the rule to the workflow with this name. Empty matches
+	// all workflows.
+	Workflow string
+	// Key matches keys exactly. Either Key or Pattern has to be set.
+	Key types.ProviderReportKey
+	// Pattern matches keys using a regular expression.
+	Pattern *regexp.Regexp
+	// Action is the redaction action to apply.
+	Action Action
+	// Length is the number of runes to keep when Action is ActionTruncate.
+	Length int
+	// Buckets are the sorted upper bounds (exclusive) of buckets used when
+	// Action is ActionBucketize. E.g. buckets 10, 100 produce labels
+	// "<10", "10-100" and ">=100".
+	Buckets []float64
+}
+
+func (r Rule) matches(workflow string, key types.ProviderReportKey) bool {
+	if r.Workflow != "" && r.Workflow != workflow {
+		return false
+	}
+	if r.Key != "" {
+		return r.Key == key
+	}
+	return r.Pattern.MatchString(string(key))
+}
+
+func (r Rule) validate() error {
+	if r.Key == "" && r.Pattern == nil {
+		return errors.New("either key or pattern has to be set")
+	}
+	switch r.Action {
+	case ActionDrop, ActionHash:
+	case ActionTruncate:
+		if r.Length < 0 {
+			return fmt.Errorf("invalid truncate length %d", r.Length)
+		}
+	case ActionBucketize:
+		if len(r.Buckets) == 0 {
+			return errors.New("no buckets defined")
+		}
+		if !sort.Float64sAreSorted(r.Buckets) {
+			return errors.New("buckets have to be sorted")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+	return nil
+}
+
+// Redactor redacts reports according to configured rules.
+type Redactor struct {
+	salt  []byte
+	rules []Rule
+}
+
+// NewRedactor creates a new redactor which will apply the provided rules. For
+// every key the first matching rule is applied.
+//
+// The salt is used to key hashes produced by ActionHash rules and it should be
+// unique per installation and stable over time, so that hashed values can be
+// correlated across reports from one installation but not across installations.
+// NewSalt can be used to generate one.
+func NewRedactor(salt []byte, rules ...Rule) (*Redactor, error) {
+	for i, r := range rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %d: %w", i, err)
+		}
+		if r.Action == ActionHash && len(salt) == 0 {
+			return nil, fmt.Errorf("invalid redaction rule %d: hash action requires a salt", i)
+		}
+	}
+
+	return &Redactor{
+		salt:  salt,
+		rules: rules,
+	}, nil
+}
+
+// NewSalt generates a new random salt which can be used with NewRedactor.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return salt, nil
+}
+
+// Redact returns a redacted copy of the report. The provided report is left
+// intact.
+func (rd *Redactor) Redact(report types.Report) (types.Report, error) {
+	out := make(types.Report, len(report))
+	for _, name := range report.Names() {
+		var (
+			redacted = map[types.ProviderReportKey]Action{}
+			r, err   = rd.redactReport(name, "", report[name], redacted)
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to redact workflow %s report: %w", name, err)
+		}
+
+		if len(redacted) > 0 {
+			marks := make([]string, 0, len(redacted))
+			for k, a := range redacted {
+				marks = append(marks, fmt.Sprintf("%s=%s", k, a))
+			}
+			sort.Strings(marks)
+			r[RedactedKeysKey] = marks
+		}
+		out[name] = r
+	}
+	return out, nil
+}
+
+func (rd *Redactor) redactReport(
+	workflow string, prefix types.ProviderReportKey, report types.ProviderReport, redacted map[types.ProviderReportKey]Action,
+) (types.ProviderReport, error) {
+	out := make(types.ProviderReport, len(report))
+	for k, v := range report {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		rule, ok := rd.ruleFor(workflow, path)
+		if !ok {
+			if nested, isReport := v.(types.ProviderReport); isReport {
+				r, err := rd.redactReport(workflow, path, nested, redacted)
+				if err != nil {
+					return nil, err
+				}
+				v = r
+			}
+			out[k] = v
+			continue
+		}
+
+		redacted[path] = rule.Action
+		if rule.Action == ActionDrop {
+			continue
+		}
+		rv, err := rd.apply(rule, v)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", path, err)
+		}
+		out[k] = rv
+	}
+	return out, nil
+}
+
+func (rd *Redactor) ruleFor(workflow string, key types.ProviderReportKey) (Rule, bool) {
+	for _, r := range rd.rules {
+		if r.matches(workflow, key) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+func (rd *Redactor) apply(rule Rule, v any) (any, error) {
+	cv, t, err := types.CanonicalValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rule.Action { //nolint:exhaustive
+	case ActionHash:
+		switch t { //nolint:exhaustive
+		case types.ValueTypeStringList:
+			return mapStrings(cv.([]string), rd.hash), nil
+		case types.ValueTypeReport:
+			return nil, errors.New("cannot hash a nested report")
+		default:
+			return rd.hash(formatValue(cv, t)), nil
+		}
+
+	case ActionTruncate:
+		truncate := func(s string) string {
+			return truncateRunes(s, rule.Length)
+		}
+		switch t { //nolint:exhaustive
+		case types.ValueTypeString:
+			return truncate(cv.(string)), nil
+		case types.ValueTypeStringList:
+			return mapStrings(cv.([]string), truncate), nil
+		default:
+			return nil, fmt.Errorf("cannot truncate value of type %s", t)
+		}
+
+	case ActionBucketize:
+		switch t { //nolint:exhaustive
+		case types.ValueTypeInt:
+			return bucket(float64(cv.(int64)), rule.Buckets), nil
+		case types.ValueTypeFloat:
+			return bucket(cv.(float64), rule.Buckets), nil
+		default:
+			return nil, fmt.Errorf("cannot bucketize value of type %s", t)
+		}
+	}
+
+	return nil, fmt.Errorf("unknown action %q", rule.Action)
+}
+
+// formatValue formats the canonical value the way serializers do, so that equal
+// values always hash the same, e.g. times in different zones.
+func formatValue(cv any, t types.ValueType) string {
+	switch t { //nolint:exhaustive
+	case types.ValueTypeString:
+		return cv.(string)
+	case types.ValueTypeInt:
+		return strconv.FormatInt(cv.(int64), 10)
+	case types.ValueTypeFloat:
+		return strconv.FormatFloat(cv.(float64), 'g', -1, 64)
+	case types.ValueTypeBool:
+		return strconv.FormatBool(cv.(bool))
+	case types.ValueTypeDuration:
+		return cv.(time.Duration).String()
+	case types.ValueTypeTime:
+		return cv.(time.Time).UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(cv)
+}
+
+func (rd *Redactor) hash(s string) string {
+	mac := hmac.New(sha256.New, rd.salt)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func mapStrings(in []string, f func(string) string) []string {
+	out := make([]string, len(in))
+	for i, s := range in {
+		out[i] = f(s)
+	}
+	return out
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+func bucket(v float64, buckets []float64) string {
+	format := func(f float64) string {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	i := sort.Search(len(buckets), func(i int) bool {
+		return v < buckets[i]
+	})
+	switch i {
+	case 0:
+		return "<" + format(buckets[0])
+	case len(buckets):
+		return ">=" + format(buckets[len(buckets)-1])
+	default:
+		return format(buckets[i-1]) + "-" + format(buckets[i])
+	}
+}
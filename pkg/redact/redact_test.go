@@ -0,0 +1,150 @@
+package redact
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/provider"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestRedactor(t *testing.T) {
+	salt := []byte("install-salt")
+
+	t.Run("rules are applied and redacted keys are marked", func(t *testing.T) {
+		rd, err := NewRedactor(salt,
+			Rule{Key: provider.HostnameKey, Action: ActionHash},
+			Rule{Key: "secret", Action: ActionDrop},
+			Rule{Pattern: regexp.MustCompile(`^ns_`), Action: ActionTruncate, Length: 3},
+			Rule{Workflow: "cluster-state", Pattern: regexp.MustCompile(`_count$`), Action: ActionBucketize, Buckets: []float64{10, 100}},
+		)
+		require.NoError(t, err)
+
+		report := types.Report{
+			"state": types.ProviderReport{
+				provider.HostnameKey: "customer-host",
+				"secret":             "value",
+				"uptime":             10,
+				"nested": types.ProviderReport{
+					"secret": "nested-value",
+				},
+			},
+			"cluster-state": types.ProviderReport{
+				"k8s_pods_count":  57,
+				"k8s_nodes_count": 1000,
+				"ns_names":        []string{"customer-a", "b"},
+			},
+			"other": types.ProviderReport{
+				"k8s_pods_count": 57,
+			},
+		}
+
+		out, err := rd.Redact(report)
+		require.NoError(t, err)
+		require.Equal(t, types.Report{
+			"state": types.ProviderReport{
+				provider.HostnameKey: rd.hash("customer-host"),
+				"uptime":             10,
+				"nested": types.ProviderReport{
+					"secret": "nested-value",
+				},
+				RedactedKeysKey: []string{"hn=hash", "secret=drop"},
+			},
+			"cluster-state": types.ProviderReport{
+				"k8s_pods_count":  "10-100",
+				"k8s_nodes_count": ">=100",
+				"ns_names":        []string{"cus", "b"},
+				RedactedKeysKey:   []string{"k8s_nodes_count=bucketize", "k8s_pods_count=bucketize", "ns_names=truncate"},
+			},
+			"other": types.ProviderReport{
+				"k8s_pods_count": 57,
+			},
+		}, out)
+
+		// Original report is left intact.
+		require.Equal(t, "customer-host", report["state"][provider.HostnameKey])
+		require.NoError(t, out.Validate())
+	})
+
+	t.Run("nested keys are matched by path", func(t *testing.T) {
+		rd, err := NewRedactor(nil, Rule{Key: "nested.secret", Action: ActionDrop})
+		require.NoError(t, err)
+
+		out, err := rd.Redact(types.Report{
+			"state": types.ProviderReport{
+				"secret": "value",
+				"nested": types.ProviderReport{
+					"secret": "nested-value",
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, types.Report{
+			"state": types.ProviderReport{
+				"secret":        "value",
+				"nested":        types.ProviderReport{},
+				RedactedKeysKey: []string{"nested.secret=drop"},
+			},
+		}, out)
+	})
+
+	t.Run("hashes are keyed with the salt", func(t *testing.T) {
+		rd1, err := NewRedactor([]byte("salt-1"), Rule{Key: "hn", Action: ActionHash})
+		require.NoError(t, err)
+		rd2, err := NewRedactor([]byte("salt-2"), Rule{Key: "hn", Action: ActionHash})
+		require.NoError(t, err)
+
+		r := types.Report{"state": types.ProviderReport{"hn": "host"}}
+		out1, err := rd1.Redact(r)
+		require.NoError(t, err)
+		out1Again, err := rd1.Redact(r)
+		require.NoError(t, err)
+		out2, err := rd2.Redact(r)
+		require.NoError(t, err)
+
+		require.Equal(t, out1, out1Again)
+		require.NotEqual(t, out1["state"]["hn"], out2["state"]["hn"])
+	})
+
+	t.Run("equal values hash the same", func(t *testing.T) {
+		rd, err := NewRedactor([]byte("salt"), Rule{Key: "v", Action: ActionHash})
+		require.NoError(t, err)
+		hash := func(v any) any {
+			t.Helper()
+			out, err := rd.Redact(types.Report{"state": types.ProviderReport{"v": v}})
+			require.NoError(t, err)
+			return out["state"]["v"]
+		}
+
+		now := time.Now()
+		require.Equal(t, rd.hash(now.UTC().Format(time.RFC3339Nano)), hash(now))
+		require.Equal(t, hash(now), hash(now.In(time.FixedZone("UTC+2", 2*60*60))))
+		require.Equal(t, hash(now), hash(now.Round(0)))
+		require.Equal(t, rd.hash("1.5"), hash(1.5))
+		require.Equal(t, rd.hash("1m30s"), hash(90*time.Second))
+		require.Equal(t, hash(int32(7)), hash(int64(7)))
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		for _, r := range []Rule{
+			{Action: ActionDrop},
+			{Key: "k", Action: "unknown"},
+			{Key: "k", Action: ActionBucketize},
+			{Key: "k", Action: ActionBucketize, Buckets: []float64{10, 1}},
+			{Key: "k", Action: ActionHash},
+		} {
+			_, err := NewRedactor(nil, r)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("unsupported value for action", func(t *testing.T) {
+		rd, err := NewRedactor(nil, Rule{Key: "k", Action: ActionBucketize, Buckets: []float64{1}})
+		require.NoError(t, err)
+		_, err = rd.Redact(types.Report{"w": types.ProviderReport{"k": "string"}})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,132 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect used by JSONSchema().
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches durations formatted by Go's time.Duration.String().
+const durationPattern = `^(0|-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+// jsonSchema is a minimal subset of JSON Schema needed to describe reports.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// JSONSchema exports the schema as a JSON Schema (draft 2020-12) document which
+// describes the JSON representation of a types.Report: an object with workflow
+// names as keys and workflow reports as values. It doesn't describe the output
+// of the JSON serializer, which wraps the report in an envelope, use
+// JSONEnvelopeSchema() for that.
+//
+// Values are described as follows:
+//   - string, int, float and bool values map onto their JSON counterparts,
+//   - durations are strings in Go's time.Duration format (e.g. 1m30s),
+//   - times are RFC 3339 strings,
+//   - string lists are arrays of strings,
+//   - nested reports are objects.
+func (s Schema) JSONSchema() ([]byte, error) {
+	root, err := s.reportJSONSchema()
+	if err != nil {
+		return nil, err
+	}
+	root.Schema = JSONSchemaDraft
+	root.Title = "Telemetry report"
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// JSONEnvelopeSchema exports the schema as a JSON Schema (draft 2020-12)
+// document which describes the output of the JSON serializer
+// (serializers.NewJSON()): an object holding the report, as described by
+// JSONSchema(), under the "report" key and the signal name under the "signal"
+// key.
+func (s Schema) JSONEnvelopeSchema() ([]byte, error) {
+	report, err := s.reportJSONSchema()
+	if err != nil {
+		return nil, err
+	}
+	root := &jsonSchema{
+		Schema: JSONSchemaDraft,
+		Title:  "Telemetry report envelope",
+		Type:   "object",
+		Properties: map[string]*jsonSchema{
+			"report": report,
+			"signal": {Type: "string"},
+		},
+		Required:             []string{"report", "signal"},
+		AdditionalProperties: boolPtr(false),
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+func (s Schema) reportJSONSchema() (*jsonSchema, error) {
+	root := &jsonSchema{
+		Type:                 "object",
+		Properties:           make(map[string]*jsonSchema, len(s.Workflows)),
+		AdditionalProperties: boolPtr(s.AllowAdditionalWorkflows),
+	}
+
+	for _, w := range s.Workflows {
+		ws := &jsonSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*jsonSchema, len(w.Keys)),
+			AdditionalProperties: boolPtr(w.AllowAdditionalKeys),
+		}
+		for _, k := range w.Keys {
+			ks, err := valueJSONSchema(k.Type)
+			if err != nil {
+				return nil, fmt.Errorf("workflow %q, key %q: %w", w.Name, k.Key, err)
+			}
+			ks.Description = k.Description
+			ws.Properties[string(k.Key)] = ks
+			if k.Required {
+				ws.Required = append(ws.Required, string(k.Key))
+			}
+		}
+		root.Properties[w.Name] = ws
+		if w.Required {
+			root.Required = append(root.Required, w.Name)
+		}
+	}
+	return root, nil
+}
+
+func valueJSONSchema(t types.ValueType) (*jsonSchema, error) {
+	switch t {
+	case types.ValueTypeString:
+		return &jsonSchema{Type: "string"}, nil
+	case types.ValueTypeInt:
+		return &jsonSchema{Type: "integer"}, nil
+	case types.ValueTypeFloat:
+		return &jsonSchema{Type: "number"}, nil
+	case types.ValueTypeBool:
+		return &jsonSchema{Type: "boolean"}, nil
+	case types.ValueTypeDuration:
+		return &jsonSchema{Type: "string", Pattern: durationPattern}, nil
+	case types.ValueTypeTime:
+		return &jsonSchema{Type: "string", Format: "date-time"}, nil
+	case types.ValueTypeStringList:
+		return &jsonSchema{Type: "array", Items: &jsonSchema{Type: "string"}}, nil
+	case types.ValueTypeReport:
+		return &jsonSchema{Type: "object"}, nil
+	}
+	return nil, fmt.Errorf("unknown value type %q", t)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
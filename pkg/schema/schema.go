@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// Key describes a single key expected in a workflow's report.
+type Key struct {
+	// Key is the report key.
+	Key types.ProviderReportKey
+	// Type is the expected type of the value stored under Key.
+	Type types.ValueType
+	// Required indicates whether the key has to be present in the report.
+	Required bool
+	// Description describes the reported value.
+	Description string
+}
+
+// Workflow describes the report expected from a workflow.
+type Workflow struct {
+	// Name is the name of the workflow.
+	Name string
+	// Required indicates whether the workflow's report has to be present in
+	// the report.
+	Required bool
+	// Keys lists keys expected in workflow's report.
+	Keys []Key
+	// AllowAdditionalKeys allows keys which are not listed in Keys.
+	AllowAdditionalKeys bool
+}
+
+// Schema describes the expected shape of a types.Report: which workflows are
+// expected, which keys they report and what are the types of reported values.
+//
+// It's meant to be shared with the receiving side (e.g. by exporting it as
+// JSON Schema via JSONSchema()) so that both ends agree on the report format.
+type Schema struct {
+	// Workflows lists expected workflows.
+	Workflows []Workflow
+	// AllowAdditionalWorkflows allows workflows which are not listed in Workflows.
+	AllowAdditionalWorkflows bool
+}
+
+// ErrNonConformant is an error which indicates that a report doesn't conform
+// to a schema.
+type ErrNonConformant struct {
+	// Workflow is the name of the workflow which report doesn't conform.
+	Workflow string
+	// Key is the offending key. It's empty when the whole workflow's report
+	// is affected.
+	Key types.ProviderReportKey
+	// Reason describes the violation.
+	Reason string
+}
+
+func (e ErrNonConformant) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("workflow %q: %s", e.Workflow, e.Reason)
+	}
+	return fmt.Sprintf("workflow %q, key %q: %s", e.Workflow, e.Key, e.Reason)
+}
+
+// Validate checks whether the report conforms to the schema. It returns an error
+// wrapping ErrNonConformant for every violation found.
+func (s Schema) Validate(report types.Report) error {
+	var (
+		errs      []error
+		workflows = make(map[string]Workflow, len(s.Workflows))
+	)
+
+	for _, w := range s.Workflows {
+		workflows[w.Name] = w
+
+		r, ok := report[w.Name]
+		if !ok {
+			if w.Required {
+				errs = append(errs, ErrNonConformant{Workflow: w.Name, Reason: "missing required workflow"})
+			}
+			continue
+		}
+		errs = append(errs, w.validate(r)...)
+	}
+
+	if !s.AllowAdditionalWorkflows {
+		for _, name := range report.Names() {
+			if _, ok := workflows[name]; !ok {
+				errs = append(errs, ErrNonConformant{Workflow: name, Reason: "unexpected workflow"})
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w Workflow) validate(r types.ProviderReport) []error {
+	var (
+		errs []error
+		keys = make(map[types.ProviderReportKey]Key, len(w.Keys))
+	)
+
+	for _, k := range w.Keys {
+		keys[k.Key] = k
+
+		v, ok := r[k.Key]
+		if !ok {
+			if k.Required {
+				errs = append(errs, ErrNonConformant{Workflow: w.Name, Key: k.Key, Reason: "missing required key"})
+			}
+			continue
+		}
+
+		t, err := types.ValueTypeOf(v)
+		if err != nil {
+			errs = append(errs, ErrNonConformant{Workflow: w.Name, Key: k.Key, Reason: err.Error()})
+			continue
+		}
+		if t != k.Type {
+			errs = append(errs, ErrNonConformant{
+				Workflow: w.Name,
+				Key:      k.Key,
+				Reason:   fmt.Sprintf("expected value of type %s, got %s", k.Type, t),
+			})
+		}
+	}
+
+	if !w.AllowAdditionalKeys {
+		for _, k := range r.Keys() {
+			if _, ok := keys[k]; !ok {
+				errs = append(errs, ErrNonConformant{Workflow: w.Name, Key: k, Reason: "unexpected key"})
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+var testSchema = Schema{
+	Workflows: []Workflow{
+		{
+			Name:     "identify-platform",
+			Required: true,
+			Keys: []Key{
+				{Key: "k8sv", Type: types.ValueTypeString, Required: true},
+				{Key: "k8s_provider", Type: types.ValueTypeString},
+			},
+		},
+		{
+			Name: "state",
+			Keys: []Key{
+				{Key: "uptime", Type: types.ValueTypeInt, Required: true},
+			},
+			AllowAdditionalKeys: true,
+		},
+	},
+}
+
+func TestSchemaValidate(t *testing.T) {
+	testcases := []struct {
+		name       string
+		report     types.Report
+		violations []ErrNonConformant
+	}{
+		{
+			name: "conformant report",
+			report: types.Report{
+				"identify-platform": types.ProviderReport{
+					"k8sv": "v1.27.1",
+				},
+				"state": types.ProviderReport{
+					"uptime": 10,
+					"hn":     "host",
+				},
+			},
+		},
+		{
+			name: "missing required workflow and key",
+			report: types.Report{
+				"state": types.ProviderReport{},
+			},
+			violations: []ErrNonConformant{
+				{Workflow: "identify-platform", Reason: "missing required workflow"},
+				{Workflow: "state", Key: "uptime", Reason: "missing required key"},
+			},
+		},
+		{
+			name: "renamed key and unexpected workflow",
+			report: types.Report{
+				"identify-platform": types.ProviderReport{
+					"k8sv":          "v1.27.1",
+					"k8s_providers": "GKE",
+				},
+				"cluster-state": types.ProviderReport{},
+			},
+			violations: []ErrNonConformant{
+				{Workflow: "identify-platform", Key: "k8s_providers", Reason: "unexpected key"},
+				{Workflow: "cluster-state", Reason: "unexpected workflow"},
+			},
+		},
+		{
+			name: "type mismatch",
+			report: types.Report{
+				"identify-platform": types.ProviderReport{
+					"k8sv": 127,
+				},
+				"state": types.ProviderReport{
+					"uptime": time.Second,
+				},
+			},
+			violations: []ErrNonConformant{
+				{Workflow: "identify-platform", Key: "k8sv", Reason: "expected value of type string, got int"},
+				{Workflow: "state", Key: "uptime", Reason: "expected value of type int, got duration"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := testSchema.Validate(tc.report)
+			if len(tc.violations) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			var joined interface{ Unwrap() []error }
+			require.ErrorAs(t, err, &joined)
+			require.Len(t, joined.Unwrap(), len(tc.violations))
+			for i, v := range tc.violations {
+				require.Equal(t, v, joined.Unwrap()[i])
+			}
+		})
+	}
+}
+
+func TestSchemaJSONSchema(t *testing.T) {
+	b, err := testSchema.JSONSchema()
+	require.NoError(t, err)
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(b, &out))
+	require.Equal(t, JSONSchemaDraft, out["$schema"])
+	require.Equal(t, false, out["additionalProperties"])
+	require.Equal(t, []any{"identify-platform"}, out["required"])
+
+	properties := out["properties"].(map[string]any)
+	identifyPlatform := properties["identify-platform"].(map[string]any)
+	require.Equal(t, []any{"k8sv"}, identifyPlatform["required"])
+	require.Equal(t, map[string]any{"type": "string"},
+		identifyPlatform["properties"].(map[string]any)["k8sv"],
+	)
+	state := properties["state"].(map[string]any)
+	require.Equal(t, true, state["additionalProperties"])
+	require.Equal(t, map[string]any{"type": "integer"},
+		state["properties"].(map[string]any)["uptime"],
+	)
+
+	_, err = Schema{
+		Workflows: []Workflow{{Name: "w", Keys: []Key{{Key: "k", Type: "unknown"}}}},
+	}.JSONSchema()
+	require.Error(t, err)
+}
+
+func TestSchemaJSONEnvelopeSchema(t *testing.T) {
+	b, err := testSchema.JSONEnvelopeSchema()
+	require.NoError(t, err)
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(b, &out))
+	require.Equal(t, JSONSchemaDraft, out["$schema"])
+	require.Equal(t, false, out["additionalProperties"])
+	require.Equal(t, []any{"report", "signal"}, out["required"])
+
+	properties := out["properties"].(map[string]any)
+	require.Equal(t, map[string]any{"type": "string"}, properties["signal"])
+	report := properties["report"].(map[string]any)
+	require.NotContains(t, report, "$schema")
+	require.Equal(t, []any{"identify-platform"}, report["required"])
+	require.Contains(t, report["properties"], "identify-platform")
+}
@@ -0,0 +1,225 @@
+package serializers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+const (
+	// CloudEventsSpecVersion is the version of the CloudEvents specification
+	// implemented by the CloudEvents serializer.
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType is the content type of structured mode CloudEvents
+	// in JSON format.
+	CloudEventsContentType = "application/cloudevents+json"
+	// CloudEventsHeaderPrefix is the prefix of HTTP headers carrying CloudEvents
+	// attributes in binary mode.
+	CloudEventsHeaderPrefix = "ce-"
+
+	cloudEventsDataContentType = "application/json"
+)
+
+type cloudEvents struct {
+	source       string
+	typePrefix   string
+	timeWorkflow string
+	timeKey      types.ProviderReportKey
+	now          func() time.Time
+	newID        func() string
+}
+
+// OptCloudEvents is the option function type that can configure the CloudEvents
+// serializer.
+type OptCloudEvents func(*cloudEvents)
+
+// OptCloudEventsTypePrefix returns an option that sets a prefix prepended to the
+// signal in the event's type, e.g. "com.konghq.telemetry.".
+func OptCloudEventsTypePrefix(prefix string) OptCloudEvents {
+	return func(s *cloudEvents) {
+		s.typePrefix = prefix
+	}
+}
+
+// OptCloudEventsTime returns an option that sets the event's time to the time
+// value reported under the key of the workflow's report, e.g. a provider
+// reporting when the report was collected. Events whose report doesn't contain
+// the key get the serialization time.
+func OptCloudEventsTime(workflow string, key types.ProviderReportKey) OptCloudEvents {
+	return func(s *cloudEvents) {
+		s.timeWorkflow = workflow
+		s.timeKey = key
+	}
+}
+
+// NewCloudEvents creates a new serializer that will serialize telemetry reports
+// into CloudEvents 1.0 events in structured mode, using the JSON event format:
+//
+//	{
+//	  "specversion": "1.0",
+//	  "type": "<type prefix><signal>",
+//	  "source": "<source>",
+//	  "id": "<random UUID>",
+//	  "time": "<serialization time>",
+//	  "datacontenttype": "application/json",
+//	  "data": {
+//	    "<workflow>": {
+//	      "<key>": <value>
+//	    }
+//	  }
+//	}
+//
+// The source identifies the instance producing reports and has to be a non-empty
+// URI-reference, e.g. "urn:uuid:<installation ID>". Values in data are encoded
+// in the same way as by the JSON serializer. Use CloudEventToBinaryMode to send
+// events in binary mode.
+//
+// Every serialized report is a distinct event with a unique id, as required
+// by the specification, even when its data is the same as a previous one's.
+// Forwarders retrying to send an event send the same serialized event, so its
+// id stays the same and receivers can deduplicate it.
+func NewCloudEvents(source string, opts ...OptCloudEvents) (cloudEvents, error) {
+	if source == "" {
+		return cloudEvents{}, errors.New("source cannot be empty")
+	}
+	if _, err := url.Parse(source); err != nil {
+		return cloudEvents{}, fmt.Errorf("source has to be a URI-reference: %w", err)
+	}
+
+	s := cloudEvents{
+		source: source,
+		now:    time.Now,
+		newID:  uuid.NewString,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s, nil
+}
+
+// cloudEvent is a structured mode CloudEvent in the JSON format.
+type cloudEvent struct {
+	SpecVersion     string                    `json:"specversion"`
+	Type            string                    `json:"type"`
+	Source          string                    `json:"source"`
+	ID              string                    `json:"id"`
+	Time            string                    `json:"time"`
+	DataContentType string                    `json:"datacontenttype"`
+	Data            map[string]map[string]any `json:"data"`
+}
+
+// Serialize serializes the report into a structured mode CloudEvent.
+func (s cloudEvents) Serialize(report types.Report, signal types.Signal) ([]byte, error) {
+	ev := cloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            s.typePrefix + string(signal),
+		Source:          s.source,
+		ID:              s.newID(),
+		DataContentType: cloudEventsDataContentType,
+		Data:            make(map[string]map[string]any, len(report)),
+	}
+	for name, pr := range report {
+		m, err := jsonReport(pr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize workflow %s report: %w", name, err)
+		}
+		ev.Data[name] = m
+	}
+
+	t, err := s.time(report)
+	if err != nil {
+		return nil, err
+	}
+	ev.Time = t.UTC().Format(time.RFC3339Nano)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(ev); err != nil {
+		return nil, err
+	}
+	buf.Truncate(buf.Len() - 1)
+	return buf.Bytes(), nil
+}
+
+// time returns the time reported under the configured key, or the current
+// time when it's not configured or reported.
+func (s cloudEvents) time(report types.Report) (time.Time, error) {
+	if s.timeKey == "" {
+		return s.now(), nil
+	}
+	v, ok := report[s.timeWorkflow][s.timeKey]
+	if !ok {
+		return s.now(), nil
+	}
+	cv, vt, err := types.CanonicalValue(v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if vt != types.ValueTypeTime {
+		return time.Time{}, fmt.Errorf("workflow %s key %s holds a %s value, not a time", s.timeWorkflow, s.timeKey, vt)
+	}
+	return cv.(time.Time), nil
+}
+
+// CloudEventToBinaryMode converts a structured mode CloudEvent in the JSON format,
+// e.g. produced by the CloudEvents serializer, into binary mode as defined by
+// the CloudEvents HTTP protocol binding: attributes are returned as "ce-" prefixed
+// HTTP headers, datacontenttype as Content-Type header and data as the body.
+func CloudEventToBinaryMode(event []byte) (http.Header, []byte, error) {
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(event, &attrs); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse event: %w", err)
+	}
+	for _, required := range []string{"specversion", "type", "source", "id"} {
+		if _, ok := attrs[required]; !ok {
+			return nil, nil, fmt.Errorf("event is missing required attribute %q", required)
+		}
+	}
+	if _, ok := attrs["data_base64"]; ok {
+		return nil, nil, errors.New("events with base64 encoded data are not supported")
+	}
+
+	h := http.Header{}
+	for name, raw := range attrs {
+		if name == "data" {
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// Non string attributes, e.g. integers, keep their JSON representation.
+			value = string(raw)
+		}
+		if name == "datacontenttype" {
+			h.Set("Content-Type", value)
+			continue
+		}
+		h.Set(CloudEventsHeaderPrefix+name, percentEncodeHeaderValue(value))
+	}
+	return h, attrs["data"], nil
+}
+
+// percentEncodeHeaderValue percent-encodes space, '"', '%' and all characters
+// outside of printable ASCII as required by the CloudEvents HTTP protocol binding.
+func percentEncodeHeaderValue(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c <= ' ' || c > '~' || c == '"' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
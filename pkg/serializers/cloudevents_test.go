@@ -0,0 +1,119 @@
+package serializers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestCloudEvents(t *testing.T) {
+	s, err := NewCloudEvents("urn:uuid:8f6b3e4e-1c1a-4d1b-9a4e-0d8d6c3f2a11",
+		OptCloudEventsTypePrefix("com.konghq.telemetry."),
+		OptCloudEventsTime("state", "collected"),
+	)
+	require.NoError(t, err)
+	s.newID = func() string { return "a0f9c1e2-3b4d-4e5f-8a9b-0c1d2e3f4a5b" }
+	s.now = func() time.Time { return time.Date(2023, 1, 2, 3, 4, 6, 0, time.UTC) }
+
+	report := types.Report{
+		"identify-platform": types.ProviderReport{
+			"k8sv": "v1.27.1",
+			"d":    time.Minute,
+		},
+		"state": types.ProviderReport{
+			"collected": time.Date(2023, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+		},
+	}
+	out, err := s.Serialize(report, "kic-ping")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"specversion": "1.0",
+		"type": "com.konghq.telemetry.kic-ping",
+		"source": "urn:uuid:8f6b3e4e-1c1a-4d1b-9a4e-0d8d6c3f2a11",
+		"id": "a0f9c1e2-3b4d-4e5f-8a9b-0c1d2e3f4a5b",
+		"time": "2023-01-02T02:04:05Z",
+		"datacontenttype": "application/json",
+		"data": {
+			"identify-platform": {"d": "1m0s", "k8sv": "v1.27.1"},
+			"state": {"collected": "2023-01-02T02:04:05Z"}
+		}
+	}`, string(out))
+
+	t.Run("every event gets a unique id", func(t *testing.T) {
+		s, err := NewCloudEvents("urn:uuid:8f6b3e4e-1c1a-4d1b-9a4e-0d8d6c3f2a11")
+		require.NoError(t, err)
+		first, err := s.Serialize(report, "kic-ping")
+		require.NoError(t, err)
+		second, err := s.Serialize(report, "kic-ping")
+		require.NoError(t, err)
+		assert.NotEqual(t, eventID(t, first), eventID(t, second))
+	})
+
+	t.Run("time is the serialization time when not reported", func(t *testing.T) {
+		out, err := s.Serialize(types.Report{"state": types.ProviderReport{"uptime": 10}}, "kic-ping")
+		require.NoError(t, err)
+		var ev map[string]any
+		require.NoError(t, json.Unmarshal(out, &ev))
+		assert.Equal(t, "2023-01-02T03:04:06Z", ev["time"])
+	})
+
+	t.Run("time has to be a time value", func(t *testing.T) {
+		_, err := s.Serialize(types.Report{"state": types.ProviderReport{"collected": "yesterday"}}, "kic-ping")
+		require.Error(t, err)
+	})
+
+	t.Run("binary mode", func(t *testing.T) {
+		h, body, err := CloudEventToBinaryMode(out)
+		require.NoError(t, err)
+		assert.Equal(t, http.Header{
+			"Ce-Specversion": {"1.0"},
+			"Ce-Type":        {"com.konghq.telemetry.kic-ping"},
+			"Ce-Source":      {"urn:uuid:8f6b3e4e-1c1a-4d1b-9a4e-0d8d6c3f2a11"},
+			"Ce-Id":          {"a0f9c1e2-3b4d-4e5f-8a9b-0c1d2e3f4a5b"},
+			"Ce-Time":        {"2023-01-02T02:04:05Z"},
+			"Content-Type":   {"application/json"},
+		}, h)
+		assert.JSONEq(t, `{
+			"identify-platform": {"d": "1m0s", "k8sv": "v1.27.1"},
+			"state": {"collected": "2023-01-02T02:04:05Z"}
+		}`, string(body))
+	})
+
+	t.Run("binary mode header values are percent-encoded", func(t *testing.T) {
+		h, _, err := CloudEventToBinaryMode([]byte(`{"specversion":"1.0","type":"a b","source":"s","id":"100%","ext":"\"é\""}`))
+		require.NoError(t, err)
+		assert.Equal(t, "a%20b", h.Get("ce-type"))
+		assert.Equal(t, "100%25", h.Get("ce-id"))
+		assert.Equal(t, "%22%C3%A9%22", h.Get("ce-ext"))
+	})
+
+	t.Run("binary mode requires attributes", func(t *testing.T) {
+		_, _, err := CloudEventToBinaryMode([]byte(`{"specversion":"1.0"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("source is required", func(t *testing.T) {
+		_, err := NewCloudEvents("")
+		require.Error(t, err)
+	})
+}
+
+// eventID returns the id of the event, checking that it's a random UUID.
+func eventID(t *testing.T, event []byte) string {
+	t.Helper()
+	var ev struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(event, &ev))
+	id, err := uuid.Parse(ev.ID)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Version(4), id.Version())
+	return ev.ID
+}
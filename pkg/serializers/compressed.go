@@ -0,0 +1,180 @@
+package serializers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// Compression is a compression algorithm.
+type Compression string
+
+const (
+	// CompressionNone means that the payload is not compressed.
+	CompressionNone = Compression("")
+	// CompressionGzip is gzip (RFC 1952).
+	CompressionGzip = Compression("gzip")
+	// CompressionZstd is Zstandard (RFC 8878).
+	CompressionZstd = Compression("zstd")
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ContentEncoding returns the value of the HTTP Content-Encoding header for
+// payloads compressed with the algorithm, or an empty string for uncompressed
+// payloads.
+func (c Compression) ContentEncoding() string {
+	return string(c)
+}
+
+// DetectCompression tells whether the payload is compressed and with which
+// algorithm using the magic numbers which every gzip and zstd stream starts with.
+func DetectCompression(payload []byte) Compression {
+	switch {
+	case bytes.HasPrefix(payload, gzipMagic):
+		return CompressionGzip
+	case bytes.HasPrefix(payload, zstdMagic):
+		return CompressionZstd
+	default:
+		return CompressionNone
+	}
+}
+
+// Decompress decompresses the payload if it's compressed, as detected by
+// DetectCompression, and returns uncompressed payloads as they are. When
+// maxSize is greater than 0 payloads which decompress to more bytes are
+// rejected to protect receivers from decompression bombs.
+func Decompress(payload []byte, maxSize int64) ([]byte, error) {
+	var (
+		r   io.Reader
+		err error
+	)
+	switch DetectCompression(payload) {
+	case CompressionGzip:
+		var gr *gzip.Reader
+		if gr, err = gzip.NewReader(bytes.NewReader(payload)); err != nil {
+			return nil, fmt.Errorf("failed to read gzip payload: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	case CompressionZstd:
+		var zr *zstd.Decoder
+		if zr, err = zstd.NewReader(bytes.NewReader(payload)); err != nil {
+			return nil, fmt.Errorf("failed to read zstd payload: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	case CompressionNone:
+		return payload, nil
+	}
+
+	if maxSize > 0 {
+		r = io.LimitReader(r, maxSize+1)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress payload: %w", err)
+	}
+	if maxSize > 0 && int64(len(out)) > maxSize {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", maxSize)
+	}
+	return out, nil
+}
+
+type compressed struct {
+	serializer  serializer
+	compression Compression
+	threshold   int
+}
+
+// OptCompressed is the option function type that can configure the compressing
+// serializer.
+type OptCompressed func(*compressed)
+
+// OptCompressedAlgorithm returns an option that sets the compression algorithm.
+func OptCompressedAlgorithm(c Compression) OptCompressed {
+	return func(s *compressed) {
+		s.compression = c
+	}
+}
+
+// OptCompressedThreshold returns an option that sets the size in bytes under
+// which payloads are left uncompressed, because compression wouldn't pay off.
+func OptCompressedThreshold(threshold int) OptCompressed {
+	return func(s *compressed) {
+		s.threshold = threshold
+	}
+}
+
+// NewCompressed creates a serializer which compresses the output of the provided
+// serializer. By default gzip is used and all payloads are compressed.
+//
+// Compressed payloads can be told apart from uncompressed ones using
+// DetectCompression and decompressed with Decompress. This relies on the wrapped
+// serializer's output never starting with gzip's or zstd's magic number, which
+// holds for all serializers in this package.
+func NewCompressed(serializer serializer, opts ...OptCompressed) (compressed, error) {
+	s := compressed{
+		serializer:  serializer,
+		compression: CompressionGzip,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if serializer == nil {
+		return compressed{}, errors.New("serializer cannot be nil")
+	}
+	switch s.compression {
+	case CompressionGzip, CompressionZstd:
+	case CompressionNone:
+		return compressed{}, errors.New("compression algorithm has to be set")
+	default:
+		return compressed{}, fmt.Errorf("unsupported compression algorithm %q", s.compression)
+	}
+	if s.threshold < 0 {
+		return compressed{}, fmt.Errorf("invalid threshold %d", s.threshold)
+	}
+	return s, nil
+}
+
+// Serialize serializes the report with the wrapped serializer and compresses
+// the result when it's not smaller than the threshold.
+func (s compressed) Serialize(report types.Report, signal types.Signal) ([]byte, error) {
+	b, err := s.serializer.Serialize(report, signal)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < s.threshold {
+		return b, nil
+	}
+
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch s.compression { //nolint:exhaustive
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionZstd:
+		if w, err = zstd.NewWriter(&buf); err != nil {
+			return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+	}
+	if _, err := w.Write(b); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress payload: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,68 @@
+package serializers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestCompressed(t *testing.T) {
+	report := types.Report{
+		"identify-platform": types.ProviderReport{
+			"k8sv": "v1.27.1",
+			"long": strings.Repeat("a", 1024),
+		},
+	}
+	inner := NewJSON()
+	expected, err := inner.Serialize(report, "kic-ping")
+	require.NoError(t, err)
+
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		t.Run(string(c), func(t *testing.T) {
+			s, err := NewCompressed(inner, OptCompressedAlgorithm(c))
+			require.NoError(t, err)
+
+			out, err := s.Serialize(report, "kic-ping")
+			require.NoError(t, err)
+			assert.Less(t, len(out), len(expected))
+			assert.Equal(t, c, DetectCompression(out))
+			assert.Equal(t, string(c), DetectCompression(out).ContentEncoding())
+
+			decompressed, err := Decompress(out, 0)
+			require.NoError(t, err)
+			assert.Equal(t, expected, decompressed)
+
+			_, err = Decompress(out, 100)
+			require.Error(t, err, "payloads decompressing over the limit should be rejected")
+		})
+	}
+
+	t.Run("payloads under threshold are not compressed", func(t *testing.T) {
+		s, err := NewCompressed(inner, OptCompressedThreshold(len(expected)+1))
+		require.NoError(t, err)
+
+		out, err := s.Serialize(report, "kic-ping")
+		require.NoError(t, err)
+		assert.Equal(t, expected, out)
+		assert.Equal(t, CompressionNone, DetectCompression(out))
+
+		decompressed, err := Decompress(out, 0)
+		require.NoError(t, err)
+		assert.Equal(t, expected, decompressed)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := NewCompressed(inner, OptCompressedAlgorithm("lz4"))
+		require.Error(t, err)
+		_, err = NewCompressed(inner, OptCompressedAlgorithm(CompressionNone))
+		require.Error(t, err)
+		_, err = NewCompressed(inner, OptCompressedThreshold(-1))
+		require.Error(t, err)
+		_, err = NewCompressed(nil)
+		require.Error(t, err)
+	})
+}
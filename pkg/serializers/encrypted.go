@@ -0,0 +1,41 @@
+package serializers
+
+import (
+	"errors"
+
+	"github.com/kong/kubernetes-telemetry/pkg/encryption"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+type encrypted struct {
+	serializer serializer
+	encrypter  *encryption.Encrypter
+}
+
+// NewEncrypted creates a serializer which encrypts the output of the provided
+// serializer to the collector's public key with the encrypter, independently of
+// the transport. Collectors decrypt payloads with encryption.Decrypter.
+//
+// When combined with other wrappers, compress before encrypting, since ciphertext
+// doesn't compress, and sign the ciphertext.
+func NewEncrypted(serializer serializer, encrypter *encryption.Encrypter) (encrypted, error) {
+	if serializer == nil {
+		return encrypted{}, errors.New("serializer cannot be nil")
+	}
+	if encrypter == nil {
+		return encrypted{}, errors.New("encrypter cannot be nil")
+	}
+	return encrypted{
+		serializer: serializer,
+		encrypter:  encrypter,
+	}, nil
+}
+
+// Serialize serializes the report with the wrapped serializer and encrypts the result.
+func (s encrypted) Serialize(report types.Report, signal types.Signal) ([]byte, error) {
+	b, err := s.serializer.Serialize(report, signal)
+	if err != nil {
+		return nil, err
+	}
+	return s.encrypter.Encrypt(b)
+}
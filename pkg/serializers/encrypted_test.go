@@ -0,0 +1,34 @@
+package serializers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/encryption"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestEncrypted(t *testing.T) {
+	key, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	e, err := encryption.NewEncrypter("collector-1", key.PublicKey())
+	require.NoError(t, err)
+	d, err := encryption.NewDecrypter(encryption.DecryptionKey{ID: "collector-1", PrivateKey: key})
+	require.NoError(t, err)
+
+	s, err := NewEncrypted(NewSemicolonDelimited(), e)
+	require.NoError(t, err)
+
+	out, err := s.Serialize(types.Report{"w": types.ProviderReport{"key": "value"}}, "test-signal")
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "test-signal")
+
+	payload, _, err := d.Decrypt(out)
+	require.NoError(t, err)
+	assert.Equal(t, "<14>signal=test-signal;key=value;\n", string(payload))
+
+	_, err = NewEncrypted(NewSemicolonDelimited(), nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package serializers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+type jsonSerializer struct {
+	pretty bool
+	ndjson bool
+}
+
+// OptJSON is the option function type that can configure the JSON serializer.
+type OptJSON func(*jsonSerializer)
+
+// OptJSONPretty returns an option that makes the JSON serializer indent its
+// output. It has no effect when NDJSON framing is enabled.
+func OptJSONPretty() OptJSON {
+	return func(s *jsonSerializer) {
+		s.pretty = true
+	}
+}
+
+// OptJSONNDJSON returns an option that makes the JSON serializer produce
+// newline delimited JSON: every report is serialized as a single, compact line
+// terminated with a newline so that it can be sent over stream forwarders.
+func OptJSONNDJSON() OptJSON {
+	return func(s *jsonSerializer) {
+		s.ndjson = true
+	}
+}
+
+// NewJSON creates a new serializer that will serialize telemetry reports into
+// JSON objects of the following form:
+//
+//	{
+//	  "report": {
+//	    "<workflow>": {
+//	      "<key>": <value>
+//	    }
+//	  },
+//	  "signal": "<signal>"
+//	}
+//
+// Object keys are sorted so that the output is deterministic and can be diffed
+// and hashed. Durations are serialized as strings in Go's time.Duration format
+// and times as RFC 3339 strings in UTC.
+func NewJSON(opts ...OptJSON) jsonSerializer {
+	var s jsonSerializer
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// jsonEnvelope is the top level object produced by the JSON serializer.
+type jsonEnvelope struct {
+	Report map[string]map[string]any `json:"report"`
+	Signal types.Signal              `json:"signal"`
+}
+
+// Serialize serializes the report into JSON.
+func (s jsonSerializer) Serialize(report types.Report, signal types.Signal) ([]byte, error) {
+	env := jsonEnvelope{
+		Report: make(map[string]map[string]any, len(report)),
+		Signal: signal,
+	}
+	for name, pr := range report {
+		m, err := jsonReport(pr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize workflow %s report: %w", name, err)
+		}
+		env.Report[name] = m
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if s.pretty && !s.ndjson {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(env); err != nil {
+		return nil, err
+	}
+
+	// json.Encoder always terminates its output with a newline which is only
+	// desired for NDJSON framing.
+	if !s.ndjson {
+		buf.Truncate(buf.Len() - 1)
+	}
+	return buf.Bytes(), nil
+}
+
+func jsonReport(report types.ProviderReport) (map[string]any, error) {
+	out := make(map[string]any, len(report))
+	for k, v := range report {
+		jv, err := jsonValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize key %s: %w", k, err)
+		}
+		out[string(k)] = jv
+	}
+	return out, nil
+}
+
+func jsonValue(v any) (any, error) {
+	cv, t, err := types.CanonicalValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t { //nolint:exhaustive
+	case types.ValueTypeDuration:
+		return cv.(time.Duration).String(), nil
+	case types.ValueTypeTime:
+		return cv.(time.Time).UTC().Format(time.RFC3339Nano), nil
+	case types.ValueTypeStringList:
+		// Lists are arrays in the JSON Schema, so nil ones mustn't be null.
+		if l := cv.([]string); l != nil {
+			return l, nil
+		}
+		return []string{}, nil
+	case types.ValueTypeReport:
+		return jsonReport(cv.(types.ProviderReport))
+	default:
+		return cv, nil
+	}
+}
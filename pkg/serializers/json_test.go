@@ -0,0 +1,89 @@
+package serializers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/provider"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestJSON(t *testing.T) {
+	report := types.Report{
+		"identify-platform": types.ProviderReport{
+			"k8sv":         "v1.2.3-gke-a1fdc32f",
+			"k8s_provider": provider.ClusterProviderGKE,
+		},
+		"state": types.ProviderReport{
+			"uptime": 10,
+			"b":      true,
+			"d":      90 * time.Second,
+			"f":      1.5,
+			"l":      []string{"a", "b"},
+			"ts":     time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			"nested": types.ProviderReport{
+				"z": "<&>",
+				"a": 1,
+			},
+		},
+	}
+
+	t.Run("compact", func(t *testing.T) {
+		out, err := NewJSON().Serialize(report, "kic-ping")
+		require.NoError(t, err)
+		assert.Equal(t,
+			`{"report":{"identify-platform":{"k8s_provider":"GKE","k8sv":"v1.2.3-gke-a1fdc32f"},`+
+				`"state":{"b":true,"d":"1m30s","f":1.5,"l":["a","b"],"nested":{"a":1,"z":"<&>"},"ts":"2023-01-02T03:04:05Z","uptime":10}},`+
+				`"signal":"kic-ping"}`,
+			string(out),
+		)
+
+		// Output is deterministic.
+		for range 10 {
+			again, err := NewJSON().Serialize(report, "kic-ping")
+			require.NoError(t, err)
+			require.Equal(t, out, again)
+		}
+	})
+
+	t.Run("pretty", func(t *testing.T) {
+		out, err := NewJSON(OptJSONPretty()).Serialize(types.Report{
+			"state": types.ProviderReport{"uptime": 10},
+		}, "kic-ping")
+		require.NoError(t, err)
+		assert.Equal(t, `{
+  "report": {
+    "state": {
+      "uptime": 10
+    }
+  },
+  "signal": "kic-ping"
+}`, string(out))
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		out, err := NewJSON(OptJSONNDJSON(), OptJSONPretty()).Serialize(types.Report{
+			"state": types.ProviderReport{"uptime": 10},
+		}, "kic-ping")
+		require.NoError(t, err)
+		assert.Equal(t, `{"report":{"state":{"uptime":10}},"signal":"kic-ping"}`+"\n", string(out))
+	})
+
+	t.Run("nil list", func(t *testing.T) {
+		out, err := NewJSON().Serialize(types.Report{
+			"state": types.ProviderReport{"l": []string(nil)},
+		}, "kic-ping")
+		require.NoError(t, err)
+		assert.Equal(t, `{"report":{"state":{"l":[]}},"signal":"kic-ping"}`, string(out))
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		_, err := NewJSON().Serialize(types.Report{
+			"state": types.ProviderReport{"p": &struct{}{}},
+		}, "kic-ping")
+		require.ErrorAs(t, err, &types.ErrUnsupportedValue{})
+	})
+}
@@ -0,0 +1,66 @@
+package serializers
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kong/kubernetes-telemetry/pkg/telemetrypb"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+type protobufSerializer struct {
+	producer string
+	now      func() time.Time
+}
+
+// OptProtobuf is the option function type that can configure the Protobuf serializer.
+type OptProtobuf func(*protobufSerializer)
+
+// OptProtobufProducer returns an option that sets the producer recorded in the
+// envelope of every report, e.g. "kic/3.0.0".
+func OptProtobufProducer(producer string) OptProtobuf {
+	return func(s *protobufSerializer) {
+		s.producer = producer
+	}
+}
+
+// NewProtobuf creates a new serializer that will serialize telemetry reports
+// into the Protobuf wire format defined by telemetrypb.SignalReport.
+//
+// Every report's envelope carries the schema version and the time the report
+// was serialized. Workflows and keys are sorted and the encoding is
+// deterministic, apart from the timestamp.
+func NewProtobuf(opts ...OptProtobuf) protobufSerializer {
+	s := protobufSerializer{
+		now: time.Now,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// Serialize serializes the report into the Protobuf wire format.
+func (s protobufSerializer) Serialize(report types.Report, signal types.Signal) ([]byte, error) {
+	sr, err := telemetrypb.FromSignalReport(types.SignalReport{Signal: signal, Report: report})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize report: %w", err)
+	}
+	sr.Envelope.CreatedAt = timestamppb.New(s.now())
+	sr.Envelope.Producer = s.producer
+
+	return proto.MarshalOptions{Deterministic: true}.Marshal(sr)
+}
+
+// Deserialize parses a report serialized in the Protobuf wire format back into
+// a types.SignalReport. See telemetrypb.ToSignalReport for details.
+func (s protobufSerializer) Deserialize(b []byte) (types.SignalReport, error) {
+	var sr telemetrypb.SignalReport
+	if err := proto.Unmarshal(b, &sr); err != nil {
+		return types.SignalReport{}, fmt.Errorf("failed to unmarshal report: %w", err)
+	}
+	return telemetrypb.ToSignalReport(&sr)
+}
@@ -0,0 +1,60 @@
+package serializers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kong/kubernetes-telemetry/pkg/telemetrypb"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestProtobuf(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := NewProtobuf(OptProtobufProducer("kic/3.0.0"))
+	s.now = func() time.Time { return now }
+
+	report := types.Report{
+		"identify-platform": types.ProviderReport{
+			"k8sv": "v1.27.1",
+		},
+		"state": types.ProviderReport{
+			"uptime": 10,
+			"d":      time.Minute,
+		},
+	}
+
+	b, err := s.Serialize(report, "kic-ping")
+	require.NoError(t, err)
+
+	again, err := s.Serialize(report, "kic-ping")
+	require.NoError(t, err)
+	assert.Equal(t, b, again, "encoding should be deterministic")
+
+	var pb telemetrypb.SignalReport
+	require.NoError(t, proto.Unmarshal(b, &pb))
+	assert.Equal(t, telemetrypb.SchemaVersion, pb.GetEnvelope().GetSchemaVersion())
+	assert.Equal(t, "kic/3.0.0", pb.GetEnvelope().GetProducer())
+	assert.Equal(t, now, pb.GetEnvelope().GetCreatedAt().AsTime())
+
+	sr, err := s.Deserialize(b)
+	require.NoError(t, err)
+	assert.Equal(t, types.SignalReport{
+		Signal: "kic-ping",
+		Report: types.Report{
+			"identify-platform": types.ProviderReport{
+				"k8sv": "v1.27.1",
+			},
+			"state": types.ProviderReport{
+				"uptime": int64(10),
+				"d":      time.Minute,
+			},
+		},
+	}, sr)
+
+	_, err = s.Deserialize([]byte{0xff})
+	require.Error(t, err)
+}
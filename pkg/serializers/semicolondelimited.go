@@ -1,6 +1,7 @@
 package serializers
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -8,38 +9,291 @@ import (
 	"github.com/kong/kubernetes-telemetry/pkg/types"
 )
 
-type semicolonDelimited struct{}
+const (
+	// semicolonDelimitedPrefix is the syslog priority prefix of every serialized
+	// report unless OptSemicolonDelimitedNoPriority is used.
+	semicolonDelimitedPrefix = "<14>"
+	// signalKey is the key under which the signal is serialized.
+	signalKey = "signal"
+	// workflowSeparator separates workflow names and nested report keys from
+	// report keys when workflow prefixes are enabled.
+	workflowSeparator = '.'
+)
+
+// UnprefixedWorkflow is the workflow name under which Deserialize puts keys
+// that are not prefixed with a workflow name.
+const UnprefixedWorkflow = ""
+
+type semicolonDelimited struct {
+	workflowPrefix bool
+	noPriority     bool
+}
+
+// OptSemicolonDelimited is the option function type that can configure the
+// semicolon delimited serializer.
+type OptSemicolonDelimited func(*semicolonDelimited)
+
+// OptSemicolonDelimitedWorkflowPrefix returns an option that makes the serializer
+// prefix every key with the name of the workflow (and the keys of nested reports)
+// it belongs to, separated with a dot, e.g. "identify-platform.k8sv=v1.27.1;".
+// This way keys don't lose their namespace when reports are flattened.
+func OptSemicolonDelimitedWorkflowPrefix() OptSemicolonDelimited {
+	return func(s *semicolonDelimited) {
+		s.workflowPrefix = true
+	}
+}
+
+// OptSemicolonDelimitedNoPriority returns an option that makes the serializer
+// omit the legacy "<14>" syslog priority prefix. Use it when the output is wrapped
+// in a proper syslog message, e.g. with NewSyslog.
+func OptSemicolonDelimitedNoPriority() OptSemicolonDelimited {
+	return func(s *semicolonDelimited) {
+		s.noPriority = true
+	}
+}
 
 // NewSemicolonDelimited creates a new serializer that will serialize telemetry
 // reports into a semicolon delimited format.
-func NewSemicolonDelimited() semicolonDelimited {
-	return semicolonDelimited{}
+//
+// Keys and values are escaped with a backslash so that they can safely contain
+// ';', '=', '\' as well as line breaks which are encoded as "\n" and "\r".
+// With workflow prefixes enabled '.' in keys is escaped as well.
+func NewSemicolonDelimited(opts ...OptSemicolonDelimited) semicolonDelimited {
+	var s semicolonDelimited
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
 }
 
 func (s semicolonDelimited) Serialize(report types.Report, signal types.Signal) ([]byte, error) {
 	out := make([]string, 0, len(report))
-	for _, v := range report {
-		out = append(out, serializeReport(v))
+	for name, v := range report {
+		var prefix string
+		if s.workflowPrefix {
+			prefix = s.escapeKey(name) + string(workflowSeparator)
+		}
+		r, err := s.serializeReport(v, prefix)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
 	}
 
-	// Should this prefix go to TLSForwarder instead?
-	prefix := fmt.Sprintf("<14>signal=%s;", signal)
+	prefix := fmt.Sprintf("%s=%s;", signalKey, escape(string(signal), ""))
+	if !s.noPriority {
+		prefix = semicolonDelimitedPrefix + prefix
+	}
 
 	sort.Strings(out)
 	return []byte(prefix + strings.Join(out, "") + "\n"), nil
 }
 
-func serializeReport(report types.ProviderReport) string {
+func (s semicolonDelimited) serializeReport(report types.ProviderReport, prefix string) (string, error) {
 	var out []string
 	for k, v := range report {
 		switch vv := v.(type) {
 		case types.ProviderReport:
-			out = append(out, serializeReport(vv))
+			var nestedPrefix string
+			if s.workflowPrefix {
+				nestedPrefix = prefix + s.escapeKey(string(k)) + string(workflowSeparator)
+			}
+			r, err := s.serializeReport(vv, nestedPrefix)
+			if err != nil {
+				return "", err
+			}
+			out = append(out, r)
 		default:
-			out = append(out, fmt.Sprintf("%v=%v;", k, v))
+			fv, err := formatValue(v)
+			if err != nil {
+				return "", fmt.Errorf("failed to serialize key %s: %w", k, err)
+			}
+			out = append(out, fmt.Sprintf("%s%s=%s;", prefix, s.escapeKey(string(k)), escape(fv, "")))
 		}
 	}
 
 	sort.Strings(out)
-	return strings.Join(out, "")
+	return strings.Join(out, ""), nil
+}
+
+func (s semicolonDelimited) escapeKey(k string) string {
+	if s.workflowPrefix {
+		return escape(k, string(workflowSeparator))
+	}
+	return escape(k, "")
+}
+
+// Deserialize parses a serialized report back into a types.SignalReport.
+//
+// When the serializer is configured with workflow prefixes the report is
+// restored with its workflows and nested reports. Otherwise all keys are put
+// in the report of the UnprefixedWorkflow workflow. Since the format doesn't
+// carry value types all values are restored as strings.
+func (s semicolonDelimited) Deserialize(b []byte) (types.SignalReport, error) {
+	line := strings.TrimSuffix(string(b), "\n")
+	line = strings.TrimPrefix(line, semicolonDelimitedPrefix)
+
+	fields, err := splitUnescaped(line, ';')
+	if err != nil {
+		return types.SignalReport{}, err
+	}
+	if len(fields) == 0 || fields[len(fields)-1] != "" {
+		return types.SignalReport{}, errors.New("report has to be terminated with ';'")
+	}
+	fields = fields[:len(fields)-1]
+	if len(fields) == 0 {
+		return types.SignalReport{}, fmt.Errorf("missing %q field", signalKey)
+	}
+
+	sr := types.SignalReport{
+		Report: types.Report{},
+	}
+	for i, f := range fields {
+		kv, err := splitUnescaped(f, '=')
+		if err != nil {
+			return types.SignalReport{}, err
+		}
+		if len(kv) != 2 { //nolint:mnd
+			return types.SignalReport{}, fmt.Errorf("malformed field %q", f)
+		}
+		value, err := unescape(kv[1])
+		if err != nil {
+			return types.SignalReport{}, err
+		}
+
+		if i == 0 {
+			if kv[0] != signalKey {
+				return types.SignalReport{}, fmt.Errorf("expected %q as the first field, got %q", signalKey, kv[0])
+			}
+			sr.Signal = types.Signal(value)
+			continue
+		}
+
+		if err := s.setValue(sr.Report, kv[0], value); err != nil {
+			return types.SignalReport{}, err
+		}
+	}
+	return sr, nil
+}
+
+func (s semicolonDelimited) setValue(report types.Report, key string, value string) error {
+	if !s.workflowPrefix {
+		k, err := unescape(key)
+		if err != nil {
+			return err
+		}
+		if report[UnprefixedWorkflow] == nil {
+			report[UnprefixedWorkflow] = types.ProviderReport{}
+		}
+		report[UnprefixedWorkflow][types.ProviderReportKey(k)] = value
+		return nil
+	}
+
+	path, err := splitUnescaped(key, workflowSeparator)
+	if err != nil {
+		return err
+	}
+	if len(path) < 2 { //nolint:mnd
+		return fmt.Errorf("key %q is not prefixed with a workflow name", key)
+	}
+	for i := range path {
+		if path[i], err = unescape(path[i]); err != nil {
+			return err
+		}
+	}
+
+	workflow := path[0]
+	if report[workflow] == nil {
+		report[workflow] = types.ProviderReport{}
+	}
+	pr := report[workflow]
+	for _, k := range path[1 : len(path)-1] {
+		nested, ok := pr[types.ProviderReportKey(k)].(types.ProviderReport)
+		if !ok {
+			nested = types.ProviderReport{}
+			pr[types.ProviderReportKey(k)] = nested
+		}
+		pr = nested
+	}
+	pr[types.ProviderReportKey(path[len(path)-1])] = value
+	return nil
+}
+
+// escape escapes backslashes, field and key-value separators, line breaks and
+// the additional characters provided.
+func escape(s string, additional string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\\' || r == ';' || r == '=' || strings.ContainsRune(additional, r):
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// unescape reverses escape.
+func unescape(s string) (string, error) {
+	var (
+		b       strings.Builder
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			switch r {
+			case 'n':
+				b.WriteRune('\n')
+			case 'r':
+				b.WriteRune('\r')
+			default:
+				b.WriteRune(r)
+			}
+			escaped = false
+		case r == '\\':
+			escaped = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if escaped {
+		return "", fmt.Errorf("dangling escape character in %q", s)
+	}
+	return b.String(), nil
+}
+
+// splitUnescaped splits s on every occurrence of sep which is not escaped.
+// Escape sequences are left intact in the returned parts.
+func splitUnescaped(s string, sep rune) ([]string, error) {
+	var (
+		parts   []string
+		b       strings.Builder
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			b.WriteRune(r)
+			escaped = true
+		case r == sep:
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("dangling escape character in %q", s)
+	}
+	return append(parts, b.String()), nil
 }
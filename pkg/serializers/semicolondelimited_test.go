@@ -2,6 +2,7 @@ package serializers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -33,4 +34,123 @@ func TestSemicolonDelimited(t *testing.T) {
 		require.NoError(t, err)
 		assert.EqualValues(t, "<14>signal=kic-ping;k8s_arch=linux/arm64;k8s_provider=GKE;k8sv=v1.2.3-gke-a1fdc32f;k8sv_semver=v1.2.3;k8s_pods_count=1;k8s_services_count=2;\n", string(out))
 	})
+	t.Run("value types", func(t *testing.T) {
+		s := NewSemicolonDelimited()
+
+		out, err := s.Serialize(
+			types.Report{
+				"state": types.ProviderReport{
+					"b":  true,
+					"d":  90 * time.Second,
+					"f":  1.5,
+					"l":  []string{"a", "b"},
+					"ts": time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+				},
+			},
+			"kic-ping",
+		)
+
+		require.NoError(t, err)
+		assert.EqualValues(t, "<14>signal=kic-ping;b=true;d=1m30s;f=1.5;l=a,b;ts=2023-01-02T03:04:05Z;\n", string(out))
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		s := NewSemicolonDelimited()
+
+		_, err := s.Serialize(
+			types.Report{
+				"state": types.ProviderReport{
+					"p": &struct{}{},
+				},
+			},
+			"kic-ping",
+		)
+		require.ErrorAs(t, err, &types.ErrUnsupportedValue{})
+	})
+}
+
+func TestSemicolonDelimitedEscaping(t *testing.T) {
+	report := types.Report{
+		"state": types.ProviderReport{
+			"hn":       "host;name=a\\b\nc",
+			"k=ey;":    "v",
+			"dot.key":  "value.with.dots",
+			"uptime":   10,
+			"nested.1": types.ProviderReport{"inner": "x;y"},
+		},
+		"identify-platform": types.ProviderReport{
+			"k8sv": "v1.27.1",
+		},
+	}
+
+	t.Run("values are escaped", func(t *testing.T) {
+		out, err := NewSemicolonDelimited().Serialize(report, "kic;ping")
+		require.NoError(t, err)
+		assert.Equal(t,
+			`<14>signal=kic\;ping;dot.key=value.with.dots;hn=host\;name\=a\\b\nc;inner=x\;y;k\=ey\;=v;uptime=10;k8sv=v1.27.1;`+"\n",
+			string(out),
+		)
+	})
+
+	t.Run("workflow prefixed keys round-trip", func(t *testing.T) {
+		s := NewSemicolonDelimited(OptSemicolonDelimitedWorkflowPrefix())
+		out, err := s.Serialize(report, "kic;ping")
+		require.NoError(t, err)
+		assert.Equal(t,
+			`<14>signal=kic\;ping;identify-platform.k8sv=v1.27.1;`+
+				`state.dot\.key=value.with.dots;state.hn=host\;name\=a\\b\nc;state.k\=ey\;=v;state.nested\.1.inner=x\;y;state.uptime=10;`+"\n",
+			string(out),
+		)
+
+		sr, err := s.Deserialize(out)
+		require.NoError(t, err)
+		require.Equal(t, types.SignalReport{
+			Signal: "kic;ping",
+			Report: types.Report{
+				"state": types.ProviderReport{
+					"hn":       "host;name=a\\b\nc",
+					"k=ey;":    "v",
+					"dot.key":  "value.with.dots",
+					"uptime":   "10",
+					"nested.1": types.ProviderReport{"inner": "x;y"},
+				},
+				"identify-platform": types.ProviderReport{
+					"k8sv": "v1.27.1",
+				},
+			},
+		}, sr)
+
+		again, err := s.Serialize(sr.Report, sr.Signal)
+		require.NoError(t, err)
+		require.Equal(t, out, again)
+	})
+
+	t.Run("deserialize without workflow prefixes", func(t *testing.T) {
+		sr, err := NewSemicolonDelimited().Deserialize([]byte(`<14>signal=kic-ping;k8sv=v1.27.1;hn=a\;b;` + "\n"))
+		require.NoError(t, err)
+		require.Equal(t, types.SignalReport{
+			Signal: "kic-ping",
+			Report: types.Report{
+				UnprefixedWorkflow: types.ProviderReport{
+					"k8sv": "v1.27.1",
+					"hn":   "a;b",
+				},
+			},
+		}, sr)
+	})
+
+	t.Run("deserialize malformed input", func(t *testing.T) {
+		s := NewSemicolonDelimited(OptSemicolonDelimitedWorkflowPrefix())
+		for _, in := range []string{
+			"",
+			"<14>signal=a",
+			"<14>k=v;",
+			"<14>signal=a;k;",
+			"<14>signal=a;w.k=v\\",
+			"<14>signal=a;k=v;",
+		} {
+			_, err := s.Deserialize([]byte(in))
+			require.Errorf(t, err, "input %q", in)
+		}
+	})
 }
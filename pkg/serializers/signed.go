@@ -0,0 +1,44 @@
+package serializers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kong/kubernetes-telemetry/pkg/signing"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+type signed struct {
+	serializer serializer
+	signer     signing.Signer
+}
+
+// NewSigned creates a serializer which signs the output of the provided serializer
+// with the signer, so that receivers can verify it with signing.Verifier.
+// Use signing.RotatingSigner to be able to rotate keys without recreating
+// the serializer.
+func NewSigned(serializer serializer, signer signing.Signer) (signed, error) {
+	if serializer == nil {
+		return signed{}, errors.New("serializer cannot be nil")
+	}
+	if signer == nil {
+		return signed{}, errors.New("signer cannot be nil")
+	}
+	return signed{
+		serializer: serializer,
+		signer:     signer,
+	}, nil
+}
+
+// Serialize serializes the report with the wrapped serializer and signs the result.
+func (s signed) Serialize(report types.Report, signal types.Signal) ([]byte, error) {
+	b, err := s.serializer.Serialize(report, signal)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.signer.Sign(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign payload: %w", err)
+	}
+	return out, nil
+}
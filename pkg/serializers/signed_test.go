@@ -0,0 +1,36 @@
+package serializers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/signing"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestSigned(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 32)
+	signer, err := signing.NewHMACSigner("key-1", secret)
+	require.NoError(t, err)
+	verifier, err := signing.NewVerifier(signing.VerificationKey{ID: "key-1", Algorithm: signing.AlgorithmHMACSHA256, Secret: secret})
+	require.NoError(t, err)
+
+	inner := NewSemicolonDelimited()
+	s, err := NewSigned(inner, signer)
+	require.NoError(t, err)
+
+	report := types.Report{"w": types.ProviderReport{"key": "value"}}
+	out, err := s.Serialize(report, "test-signal")
+	require.NoError(t, err)
+
+	payload, keyID, err := verifier.Verify(out)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", keyID)
+	assert.Equal(t, "<14>signal=test-signal;key=value;\n", string(payload))
+
+	_, err = NewSigned(inner, nil)
+	require.Error(t, err)
+}
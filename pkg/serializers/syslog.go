@@ -0,0 +1,377 @@
+package serializers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// SyslogFacility is the syslog facility as defined in RFC 5424, section 6.2.1.
+type SyslogFacility int
+
+const (
+	// SyslogFacilityKern is used for kernel messages.
+	SyslogFacilityKern SyslogFacility = iota
+	// SyslogFacilityUser is used for user-level messages.
+	SyslogFacilityUser
+	// SyslogFacilityMail is used for mail system messages.
+	SyslogFacilityMail
+	// SyslogFacilityDaemon is used for system daemon messages.
+	SyslogFacilityDaemon
+	// SyslogFacilityAuth is used for security and authorization messages.
+	SyslogFacilityAuth
+	// SyslogFacilitySyslog is used for messages generated internally by syslogd.
+	SyslogFacilitySyslog
+	// SyslogFacilityLPR is used for line printer subsystem messages.
+	SyslogFacilityLPR
+	// SyslogFacilityNews is used for network news subsystem messages.
+	SyslogFacilityNews
+	// SyslogFacilityUUCP is used for UUCP subsystem messages.
+	SyslogFacilityUUCP
+	// SyslogFacilityCron is used for clock (cron) daemon messages.
+	SyslogFacilityCron
+	// SyslogFacilityAuthPriv is used for private security and authorization messages.
+	SyslogFacilityAuthPriv
+	// SyslogFacilityFTP is used for FTP daemon messages.
+	SyslogFacilityFTP
+	// SyslogFacilityNTP is used for NTP subsystem messages.
+	SyslogFacilityNTP
+	// SyslogFacilityAudit is used for log audit messages.
+	SyslogFacilityAudit
+	// SyslogFacilityAlert is used for log alert messages.
+	SyslogFacilityAlert
+	// SyslogFacilityClock is used for clock daemon messages.
+	SyslogFacilityClock
+	// SyslogFacilityLocal0 is reserved for local use.
+	SyslogFacilityLocal0
+	// SyslogFacilityLocal1 is reserved for local use.
+	SyslogFacilityLocal1
+	// SyslogFacilityLocal2 is reserved for local use.
+	SyslogFacilityLocal2
+	// SyslogFacilityLocal3 is reserved for local use.
+	SyslogFacilityLocal3
+	// SyslogFacilityLocal4 is reserved for local use.
+	SyslogFacilityLocal4
+	// SyslogFacilityLocal5 is reserved for local use.
+	SyslogFacilityLocal5
+	// SyslogFacilityLocal6 is reserved for local use.
+	SyslogFacilityLocal6
+	// SyslogFacilityLocal7 is reserved for local use.
+	SyslogFacilityLocal7
+)
+
+// SyslogSeverity is the syslog severity as defined in RFC 5424, section 6.2.1.
+type SyslogSeverity int
+
+const (
+	// SyslogSeverityEmergency means that the system is unusable.
+	SyslogSeverityEmergency SyslogSeverity = iota
+	// SyslogSeverityAlert means that action must be taken immediately.
+	SyslogSeverityAlert
+	// SyslogSeverityCritical marks critical conditions.
+	SyslogSeverityCritical
+	// SyslogSeverityError marks error conditions.
+	SyslogSeverityError
+	// SyslogSeverityWarning marks warning conditions.
+	SyslogSeverityWarning
+	// SyslogSeverityNotice marks normal but significant conditions.
+	SyslogSeverityNotice
+	// SyslogSeverityInfo marks informational messages.
+	SyslogSeverityInfo
+	// SyslogSeverityDebug marks debug-level messages.
+	SyslogSeverityDebug
+)
+
+// SyslogFraming defines how syslog messages are framed on the wire, as defined
+// in RFC 6587.
+type SyslogFraming string
+
+const (
+	// SyslogFramingNone produces bare messages, e.g. for UDP transports where
+	// every datagram carries exactly one message (RFC 5426).
+	SyslogFramingNone = SyslogFraming("none")
+	// SyslogFramingNonTransparent terminates every message with a LF (RFC 6587,
+	// section 3.4.2).
+	SyslogFramingNonTransparent = SyslogFraming("non-transparent")
+	// SyslogFramingOctetCounting prefixes every message with its length in bytes
+	// followed by a space (RFC 6587, section 3.4.1, RFC 5425), which is the
+	// preferred framing for TCP and TLS transports.
+	SyslogFramingOctetCounting = SyslogFraming("octet-counting")
+)
+
+// SyslogSDParam is a structured data parameter.
+type SyslogSDParam struct {
+	Name  string
+	Value string
+}
+
+// SyslogSDElement is a structured data element as defined in RFC 5424,
+// section 6.3.
+type SyslogSDElement struct {
+	ID     string
+	Params []SyslogSDParam
+}
+
+const (
+	syslogVersion  = 1
+	syslogNilValue = "-"
+
+	syslogMaxHostnameLen = 255
+	syslogMaxAppNameLen  = 48
+	syslogMaxProcIDLen   = 128
+	syslogMaxMsgIDLen    = 32
+	syslogMaxSDNameLen   = 32
+
+	// syslogTimestampFormat is RFC 3339 with at most 6 fractional digits as
+	// required by RFC 5424, section 6.2.3.
+	syslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// serializer mirrors telemetry.Serializer which can't be imported here because
+// telemetry's tests depend on this package.
+type serializer interface {
+	Serialize(report types.Report, signal types.Signal) ([]byte, error)
+}
+
+type syslogSerializer struct {
+	serializer     serializer
+	facility       SyslogFacility
+	severity       SyslogSeverity
+	hostname       string
+	appName        string
+	procID         string
+	structuredData []SyslogSDElement
+	framing        SyslogFraming
+	now            func() time.Time
+}
+
+// OptSyslog is the option function type that can configure the syslog serializer.
+type OptSyslog func(*syslogSerializer)
+
+// OptSyslogFacility returns an option that sets the syslog facility.
+func OptSyslogFacility(f SyslogFacility) OptSyslog {
+	return func(s *syslogSerializer) {
+		s.facility = f
+	}
+}
+
+// OptSyslogSeverity returns an option that sets the syslog severity.
+func OptSyslogSeverity(sev SyslogSeverity) OptSyslog {
+	return func(s *syslogSerializer) {
+		s.severity = sev
+	}
+}
+
+// OptSyslogHostname returns an option that sets the HOSTNAME header field.
+func OptSyslogHostname(hostname string) OptSyslog {
+	return func(s *syslogSerializer) {
+		s.hostname = hostname
+	}
+}
+
+// OptSyslogAppName returns an option that sets the APP-NAME header field.
+func OptSyslogAppName(appName string) OptSyslog {
+	return func(s *syslogSerializer) {
+		s.appName = appName
+	}
+}
+
+// OptSyslogProcID returns an option that sets the PROCID header field.
+func OptSyslogProcID(procID string) OptSyslog {
+	return func(s *syslogSerializer) {
+		s.procID = procID
+	}
+}
+
+// OptSyslogStructuredData returns an option that adds structured data elements
+// to every message.
+func OptSyslogStructuredData(elements ...SyslogSDElement) OptSyslog {
+	return func(s *syslogSerializer) {
+		s.structuredData = append(s.structuredData, elements...)
+	}
+}
+
+// OptSyslogFraming returns an option that sets the framing of messages.
+func OptSyslogFraming(f SyslogFraming) OptSyslog {
+	return func(s *syslogSerializer) {
+		s.framing = f
+	}
+}
+
+// NewSyslog creates a serializer which wraps the output of the provided serializer
+// in an RFC 5424 syslog message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [STRUCTURED-DATA] MSG
+//
+// where MSGID is the report's signal and MSG is the output of the wrapped
+// serializer with trailing line breaks removed.
+//
+// By default the facility is user, the severity is informational (giving the
+// same <14> priority as the semicolon delimited serializer uses), HOSTNAME,
+// APP-NAME and PROCID are not set and messages are terminated with a LF.
+// Use a wrapped serializer which doesn't add a priority prefix of its own.
+func NewSyslog(serializer serializer, opts ...OptSyslog) (syslogSerializer, error) {
+	s := syslogSerializer{
+		serializer: serializer,
+		facility:   SyslogFacilityUser,
+		severity:   SyslogSeverityInfo,
+		framing:    SyslogFramingNonTransparent,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	if serializer == nil {
+		return syslogSerializer{}, errors.New("serializer cannot be nil")
+	}
+	if s.facility < SyslogFacilityKern || s.facility > SyslogFacilityLocal7 {
+		return syslogSerializer{}, fmt.Errorf("invalid syslog facility %d", s.facility)
+	}
+	if s.severity < SyslogSeverityEmergency || s.severity > SyslogSeverityDebug {
+		return syslogSerializer{}, fmt.Errorf("invalid syslog severity %d", s.severity)
+	}
+	switch s.framing {
+	case SyslogFramingNone, SyslogFramingNonTransparent, SyslogFramingOctetCounting:
+	default:
+		return syslogSerializer{}, fmt.Errorf("invalid syslog framing %q", s.framing)
+	}
+	for name, f := range map[string]struct {
+		value  string
+		maxLen int
+	}{
+		"hostname": {s.hostname, syslogMaxHostnameLen},
+		"app name": {s.appName, syslogMaxAppNameLen},
+		"proc ID":  {s.procID, syslogMaxProcIDLen},
+	} {
+		if !isSyslogHeaderValue(f.value, f.maxLen) {
+			return syslogSerializer{}, fmt.Errorf("invalid syslog %s %q", name, f.value)
+		}
+	}
+	for _, e := range s.structuredData {
+		if !isSyslogSDName(e.ID) {
+			return syslogSerializer{}, fmt.Errorf("invalid syslog structured data ID %q", e.ID)
+		}
+		for _, p := range e.Params {
+			if !isSyslogSDName(p.Name) {
+				return syslogSerializer{}, fmt.Errorf("invalid syslog structured data param name %q in %s", p.Name, e.ID)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// Serialize serializes the report with the wrapped serializer and wraps the
+// result in a syslog message.
+func (s syslogSerializer) Serialize(report types.Report, signal types.Signal) ([]byte, error) {
+	msg, err := s.serializer.Serialize(report, signal)
+	if err != nil {
+		return nil, err
+	}
+	msg = bytes.TrimRight(msg, "\r\n")
+
+	var b bytes.Buffer
+	b.WriteString("<" + strconv.Itoa(int(s.facility)*8+int(s.severity)) + ">") //nolint:mnd
+	b.WriteString(strconv.Itoa(syslogVersion))
+	for _, field := range []string{
+		s.now().Format(syslogTimestampFormat),
+		nilIfEmpty(s.hostname),
+		nilIfEmpty(s.appName),
+		nilIfEmpty(s.procID),
+		nilIfEmpty(syslogMsgID(signal)),
+		s.formatStructuredData(),
+	} {
+		b.WriteByte(' ')
+		b.WriteString(field)
+	}
+	if len(msg) > 0 {
+		b.WriteByte(' ')
+		b.Write(msg)
+	}
+
+	switch s.framing {
+	case SyslogFramingOctetCounting:
+		return append([]byte(strconv.Itoa(b.Len())+" "), b.Bytes()...), nil
+	case SyslogFramingNonTransparent:
+		b.WriteByte('\n')
+	case SyslogFramingNone:
+	}
+	return b.Bytes(), nil
+}
+
+func (s syslogSerializer) formatStructuredData() string {
+	if len(s.structuredData) == 0 {
+		return syslogNilValue
+	}
+
+	var b strings.Builder
+	for _, e := range s.structuredData {
+		b.WriteString("[" + e.ID)
+		for _, p := range e.Params {
+			b.WriteString(" " + p.Name + `="` + escapeSDParamValue(p.Value) + `"`)
+		}
+		b.WriteString("]")
+	}
+	return b.String()
+}
+
+// syslogMsgID converts the signal into a valid MSGID by replacing characters
+// which are not allowed and truncating it to the maximum allowed length.
+func syslogMsgID(signal types.Signal) string {
+	id := []byte(signal)
+	for i, c := range id {
+		if !isPrintUSASCII(c) {
+			id[i] = '_'
+		}
+	}
+	if len(id) > syslogMaxMsgIDLen {
+		id = id[:syslogMaxMsgIDLen]
+	}
+	return string(id)
+}
+
+func nilIfEmpty(s string) string {
+	if s == "" {
+		return syslogNilValue
+	}
+	return s
+}
+
+// escapeSDParamValue escapes '"', '\' and ']' as required by RFC 5424, section 6.3.3.
+func escapeSDParamValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
+
+func isPrintUSASCII(c byte) bool {
+	return c >= 33 && c <= 126
+}
+
+func isSyslogHeaderValue(v string, maxLen int) bool {
+	if len(v) > maxLen {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		if !isPrintUSASCII(v[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSyslogSDName(n string) bool {
+	if n == "" || len(n) > syslogMaxSDNameLen {
+		return false
+	}
+	for i := 0; i < len(n); i++ {
+		if c := n[i]; !isPrintUSASCII(c) || c == '=' || c == ']' || c == '"' {
+			return false
+		}
+	}
+	return true
+}
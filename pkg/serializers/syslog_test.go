@@ -0,0 +1,93 @@
+package serializers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestSyslog(t *testing.T) {
+	report := types.Report{
+		"identify-platform": types.ProviderReport{
+			"k8sv": "v1.27.1",
+		},
+	}
+	now := func() time.Time {
+		return time.Date(2023, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	}
+
+	testcases := []struct {
+		name     string
+		signal   types.Signal
+		opts     []OptSyslog
+		expected string
+	}{
+		{
+			name:     "defaults",
+			signal:   "kic-ping",
+			expected: "<14>1 2023-01-02T03:04:05.123456Z - - - kic-ping - signal=kic-ping;k8sv=v1.27.1;\n",
+		},
+		{
+			name:   "all header fields and structured data",
+			signal: "kic-ping",
+			opts: []OptSyslog{
+				OptSyslogFacility(SyslogFacilityLocal0),
+				OptSyslogSeverity(SyslogSeverityNotice),
+				OptSyslogHostname("host"),
+				OptSyslogAppName("kic"),
+				OptSyslogProcID("42"),
+				OptSyslogStructuredData(
+					SyslogSDElement{ID: "meta@12345", Params: []SyslogSDParam{{Name: "v", Value: `a"b\c]d`}}},
+					SyslogSDElement{ID: "empty@12345"},
+				),
+				OptSyslogFraming(SyslogFramingNone),
+			},
+			expected: `<133>1 2023-01-02T03:04:05.123456Z host kic 42 kic-ping [meta@12345 v="a\"b\\c\]d"][empty@12345] signal=kic-ping;k8sv=v1.27.1;`,
+		},
+		{
+			name:     "octet counting",
+			signal:   "kic-ping",
+			opts:     []OptSyslog{OptSyslogFraming(SyslogFramingOctetCounting)},
+			expected: "80 <14>1 2023-01-02T03:04:05.123456Z - - - kic-ping - signal=kic-ping;k8sv=v1.27.1;",
+		},
+		{
+			name:     "msgid is sanitized and truncated",
+			signal:   "a signal with spaces that is much too long",
+			opts:     []OptSyslog{OptSyslogFraming(SyslogFramingNone)},
+			expected: `<14>1 2023-01-02T03:04:05.123456Z - - - a_signal_with_spaces_that_is_muc - signal=a signal with spaces that is much too long;k8sv=v1.27.1;`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewSyslog(NewSemicolonDelimited(OptSemicolonDelimitedNoPriority()), tc.opts...)
+			require.NoError(t, err)
+			s.now = now
+
+			out, err := s.Serialize(report, tc.signal)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+
+	t.Run("invalid options are rejected", func(t *testing.T) {
+		inner := NewSemicolonDelimited(OptSemicolonDelimitedNoPriority())
+		for _, opts := range [][]OptSyslog{
+			{OptSyslogFacility(24)},
+			{OptSyslogSeverity(-1)},
+			{OptSyslogHostname("host name")},
+			{OptSyslogFraming("none-such")},
+			{OptSyslogStructuredData(SyslogSDElement{ID: "a=b"})},
+			{OptSyslogStructuredData(SyslogSDElement{ID: "id", Params: []SyslogSDParam{{Name: ""}}})},
+		} {
+			_, err := NewSyslog(inner, opts...)
+			assert.Error(t, err)
+		}
+		_, err := NewSyslog(nil)
+		assert.Error(t, err)
+	})
+}
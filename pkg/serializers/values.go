@@ -0,0 +1,41 @@
+package serializers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// formatValue formats a report value into its textual representation so that
+// each value type is formatted in the same way regardless of the serializer.
+//
+// Nested reports are not supported here and have to be handled by the caller.
+func formatValue(v any) (string, error) {
+	cv, t, err := types.CanonicalValue(v)
+	if err != nil {
+		return "", err
+	}
+
+	switch t {
+	case types.ValueTypeString:
+		return cv.(string), nil
+	case types.ValueTypeInt:
+		return strconv.FormatInt(cv.(int64), 10), nil
+	case types.ValueTypeFloat:
+		return strconv.FormatFloat(cv.(float64), 'g', -1, 64), nil
+	case types.ValueTypeBool:
+		return strconv.FormatBool(cv.(bool)), nil
+	case types.ValueTypeDuration:
+		return cv.(time.Duration).String(), nil
+	case types.ValueTypeTime:
+		return cv.(time.Time).UTC().Format(time.RFC3339Nano), nil
+	case types.ValueTypeStringList:
+		return strings.Join(cv.([]string), ","), nil
+	case types.ValueTypeReport:
+		return "", fmt.Errorf("nested report cannot be formatted as a single value")
+	}
+	return "", fmt.Errorf("unknown value type %s", t)
+}
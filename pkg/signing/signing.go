@@ -0,0 +1,308 @@
+// Package signing signs serialized telemetry reports so that receivers can tell
+// genuine reports from spoofed or tampered ones.
+//
+// A signed payload is the original payload preceded by a single header line:
+//
+//	ktsig1 <algorithm> <key ID> <base64url signature>\n<payload>
+//
+// The signature covers the header without the signature, i.e.
+// "ktsig1 <algorithm> <key ID>\n", followed by the payload, so that neither
+// the algorithm nor the key ID can be swapped.
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Algorithm is a signature algorithm.
+type Algorithm string
+
+const (
+	// AlgorithmHMACSHA256 is HMAC with SHA-256 using a shared secret.
+	AlgorithmHMACSHA256 = Algorithm("hs256")
+	// AlgorithmEd25519 is Ed25519 using a key pair.
+	AlgorithmEd25519 = Algorithm("ed25519")
+)
+
+const (
+	// headerPrefix identifies signed payloads and the version of the format.
+	headerPrefix = "ktsig1"
+	// headerFields is the number of space separated fields in the header.
+	headerFields = 4
+	// minHMACSecretLength is the minimum length of HMAC secrets in bytes.
+	minHMACSecretLength = 32
+)
+
+var encoding = base64.RawURLEncoding
+
+// ErrNotSigned is returned when verifying a payload without a signature header.
+var ErrNotSigned = errors.New("payload is not signed")
+
+// ErrUnknownKey is returned when a payload is signed with a key which is not
+// known to the verifier, e.g. because it has been rotated out.
+type ErrUnknownKey struct {
+	KeyID     string
+	Algorithm Algorithm
+}
+
+func (e ErrUnknownKey) Error() string {
+	return fmt.Sprintf("unknown %s key %q", e.Algorithm, e.KeyID)
+}
+
+// ErrInvalidSignature is returned when a signature doesn't match the payload.
+type ErrInvalidSignature struct {
+	KeyID string
+}
+
+func (e ErrInvalidSignature) Error() string {
+	return fmt.Sprintf("invalid signature made with key %q", e.KeyID)
+}
+
+// Signer signs payloads.
+type Signer interface {
+	// Sign returns the signed payload.
+	Sign(payload []byte) ([]byte, error)
+}
+
+// keySigner signs payloads with a single key.
+type keySigner struct {
+	keyID     string
+	algorithm Algorithm
+	sign      func([]byte) []byte
+}
+
+// NewHMACSigner creates a signer which signs payloads with HMAC-SHA256 using
+// the provided shared secret of at least 32 bytes.
+func NewHMACSigner(keyID string, secret []byte) (Signer, error) {
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	if len(secret) < minHMACSecretLength {
+		return nil, fmt.Errorf("HMAC secret has to be at least %d bytes long", minHMACSecretLength)
+	}
+	secret = bytes.Clone(secret)
+	return keySigner{
+		keyID:     keyID,
+		algorithm: AlgorithmHMACSHA256,
+		sign: func(b []byte) []byte {
+			return hmacSHA256(secret, b)
+		},
+	}, nil
+}
+
+// NewEd25519Signer creates a signer which signs payloads with Ed25519 using the
+// provided private key.
+func NewEd25519Signer(keyID string, key ed25519.PrivateKey) (Signer, error) {
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 private key length %d", len(key))
+	}
+	key = bytes.Clone(key)
+	return keySigner{
+		keyID:     keyID,
+		algorithm: AlgorithmEd25519,
+		sign: func(b []byte) []byte {
+			return ed25519.Sign(key, b)
+		},
+	}, nil
+}
+
+// Sign returns the signed payload.
+func (s keySigner) Sign(payload []byte) ([]byte, error) {
+	header := signedHeader(s.algorithm, s.keyID)
+	sig := s.sign(append([]byte(header+"\n"), payload...))
+
+	out := make([]byte, 0, len(header)+1+encoding.EncodedLen(len(sig))+1+len(payload))
+	out = append(out, header...)
+	out = append(out, ' ')
+	out = append(out, encoding.EncodeToString(sig)...)
+	out = append(out, '\n')
+	return append(out, payload...), nil
+}
+
+// RotatingSigner is a signer which delegates to the current signer and allows
+// to rotate it while in use. It's safe for concurrent use.
+type RotatingSigner struct {
+	lock    sync.RWMutex
+	current Signer
+}
+
+// NewRotatingSigner creates a rotating signer which starts with the provided signer.
+func NewRotatingSigner(initial Signer) (*RotatingSigner, error) {
+	if initial == nil {
+		return nil, errors.New("signer cannot be nil")
+	}
+	return &RotatingSigner{
+		current: initial,
+	}, nil
+}
+
+// Rotate replaces the current signer. Receivers should accept the new key
+// before the rotation and keep accepting the old one until reports signed with
+// it can no longer arrive.
+func (s *RotatingSigner) Rotate(next Signer) error {
+	if next == nil {
+		return errors.New("signer cannot be nil")
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.current = next
+	return nil
+}
+
+// Sign signs the payload with the current signer.
+func (s *RotatingSigner) Sign(payload []byte) ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.current.Sign(payload)
+}
+
+// VerificationKey is a key which verifies signatures made with the key ID.
+// Secret has to be set for AlgorithmHMACSHA256 and PublicKey for AlgorithmEd25519.
+type VerificationKey struct {
+	ID        string
+	Algorithm Algorithm
+	Secret    []byte
+	PublicKey ed25519.PublicKey
+}
+
+func (k VerificationKey) validate() error {
+	if err := validateKeyID(k.ID); err != nil {
+		return err
+	}
+	switch k.Algorithm {
+	case AlgorithmHMACSHA256:
+		if len(k.Secret) < minHMACSecretLength {
+			return fmt.Errorf("HMAC secret of key %q has to be at least %d bytes long", k.ID, minHMACSecretLength)
+		}
+	case AlgorithmEd25519:
+		if len(k.PublicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid Ed25519 public key length %d of key %q", len(k.PublicKey), k.ID)
+		}
+	default:
+		return fmt.Errorf("unknown algorithm %q of key %q", k.Algorithm, k.ID)
+	}
+	return nil
+}
+
+type keyRef struct {
+	id        string
+	algorithm Algorithm
+}
+
+// Verifier verifies signed payloads. Keys can be added and removed while the
+// verifier is in use to support key rotation. It's safe for concurrent use.
+type Verifier struct {
+	lock sync.RWMutex
+	keys map[keyRef]VerificationKey
+}
+
+// NewVerifier creates a verifier which accepts signatures made with any of the
+// provided keys.
+func NewVerifier(keys ...VerificationKey) (*Verifier, error) {
+	v := &Verifier{
+		keys: make(map[keyRef]VerificationKey, len(keys)),
+	}
+	for _, k := range keys {
+		if err := v.AddKey(k); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// AddKey adds a key, replacing the key with the same ID and algorithm if any.
+func (v *Verifier) AddKey(k VerificationKey) error {
+	if err := k.validate(); err != nil {
+		return err
+	}
+	k.Secret = bytes.Clone(k.Secret)
+	k.PublicKey = bytes.Clone(k.PublicKey)
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.keys[keyRef{k.ID, k.Algorithm}] = k
+	return nil
+}
+
+// RemoveKey removes the key with the ID and algorithm.
+func (v *Verifier) RemoveKey(id string, algorithm Algorithm) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.keys, keyRef{id, algorithm})
+}
+
+// Verify verifies the signed payload and returns the original payload along
+// with the ID of the key it was signed with. It returns ErrNotSigned for
+// payloads without a signature header, ErrUnknownKey for signatures made with
+// unknown keys and ErrInvalidSignature for signatures that don't match.
+func (v *Verifier) Verify(signed []byte) ([]byte, string, error) {
+	line, payload, ok := bytes.Cut(signed, []byte("\n"))
+	if !ok || !bytes.HasPrefix(line, []byte(headerPrefix+" ")) {
+		return nil, "", ErrNotSigned
+	}
+	fields := strings.Split(string(line), " ")
+	if len(fields) != headerFields {
+		return nil, "", fmt.Errorf("malformed signature header %q", line)
+	}
+	var (
+		algorithm = Algorithm(fields[1])
+		keyID     = fields[2]
+	)
+	sig, err := encoding.DecodeString(fields[3])
+	if err != nil {
+		return nil, "", fmt.Errorf("malformed signature: %w", err)
+	}
+
+	v.lock.RLock()
+	key, ok := v.keys[keyRef{keyID, algorithm}]
+	v.lock.RUnlock()
+	if !ok {
+		return nil, "", ErrUnknownKey{KeyID: keyID, Algorithm: algorithm}
+	}
+
+	signedInput := append([]byte(signedHeader(algorithm, keyID)+"\n"), payload...)
+	var valid bool
+	switch key.Algorithm {
+	case AlgorithmHMACSHA256:
+		valid = hmac.Equal(sig, hmacSHA256(key.Secret, signedInput))
+	case AlgorithmEd25519:
+		valid = ed25519.Verify(key.PublicKey, signedInput, sig)
+	}
+	if !valid {
+		return nil, "", ErrInvalidSignature{KeyID: keyID}
+	}
+	return payload, keyID, nil
+}
+
+func signedHeader(algorithm Algorithm, keyID string) string {
+	return headerPrefix + " " + string(algorithm) + " " + keyID
+}
+
+func hmacSHA256(secret, b []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(b)
+	return mac.Sum(nil)
+}
+
+func validateKeyID(id string) error {
+	if id == "" {
+		return errors.New("key ID cannot be empty")
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return fmt.Errorf("key ID %q can only contain printable ASCII characters other than space", id)
+		}
+	}
+	return nil
+}
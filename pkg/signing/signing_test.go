@@ -0,0 +1,107 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 32)
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	hmacSigner, err := NewHMACSigner("hmac-1", secret)
+	require.NoError(t, err)
+	edSigner, err := NewEd25519Signer("ed-1", priv)
+	require.NoError(t, err)
+
+	v, err := NewVerifier(
+		VerificationKey{ID: "hmac-1", Algorithm: AlgorithmHMACSHA256, Secret: secret},
+		VerificationKey{ID: "ed-1", Algorithm: AlgorithmEd25519, PublicKey: pub},
+	)
+	require.NoError(t, err)
+
+	payload := []byte("<14>signal=test;key=value;\n")
+	for name, s := range map[string]Signer{"hmac-1": hmacSigner, "ed-1": edSigner} {
+		t.Run(name, func(t *testing.T) {
+			signed, err := s.Sign(payload)
+			require.NoError(t, err)
+			assert.True(t, bytes.HasSuffix(signed, payload))
+
+			out, keyID, err := v.Verify(signed)
+			require.NoError(t, err)
+			assert.Equal(t, payload, out)
+			assert.Equal(t, name, keyID)
+
+			tampered := bytes.Clone(signed)
+			tampered[len(tampered)-3] = 'X'
+			_, _, err = v.Verify(tampered)
+			var invalid ErrInvalidSignature
+			require.ErrorAs(t, err, &invalid)
+		})
+	}
+
+	t.Run("algorithm cannot be swapped", func(t *testing.T) {
+		signed, err := hmacSigner.Sign(payload)
+		require.NoError(t, err)
+		swapped := bytes.Replace(signed, []byte("hs256 hmac-1"), []byte("ed25519 ed-1"), 1)
+		_, _, err = v.Verify(swapped)
+		require.Error(t, err)
+	})
+
+	t.Run("not signed", func(t *testing.T) {
+		_, _, err := v.Verify(payload)
+		require.ErrorIs(t, err, ErrNotSigned)
+	})
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldSigner, err := NewHMACSigner("old", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	newSigner, err := NewHMACSigner("new", bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+
+	s, err := NewRotatingSigner(oldSigner)
+	require.NoError(t, err)
+	v, err := NewVerifier(VerificationKey{ID: "old", Algorithm: AlgorithmHMACSHA256, Secret: bytes.Repeat([]byte{1}, 32)})
+	require.NoError(t, err)
+
+	signedWithOld, err := s.Sign([]byte("payload"))
+	require.NoError(t, err)
+
+	// Receivers start accepting the new key before signers rotate.
+	require.NoError(t, v.AddKey(VerificationKey{ID: "new", Algorithm: AlgorithmHMACSHA256, Secret: bytes.Repeat([]byte{2}, 32)}))
+	require.NoError(t, s.Rotate(newSigner))
+	signedWithNew, err := s.Sign([]byte("payload"))
+	require.NoError(t, err)
+
+	_, keyID, err := v.Verify(signedWithOld)
+	require.NoError(t, err)
+	assert.Equal(t, "old", keyID)
+	_, keyID, err = v.Verify(signedWithNew)
+	require.NoError(t, err)
+	assert.Equal(t, "new", keyID)
+
+	v.RemoveKey("old", AlgorithmHMACSHA256)
+	_, _, err = v.Verify(signedWithOld)
+	var unknown ErrUnknownKey
+	require.ErrorAs(t, err, &unknown)
+	assert.Equal(t, "old", unknown.KeyID)
+}
+
+func TestInvalidKeys(t *testing.T) {
+	_, err := NewHMACSigner("id", []byte("short"))
+	require.Error(t, err)
+	_, err = NewHMACSigner("with space", bytes.Repeat([]byte{1}, 32))
+	require.Error(t, err)
+	_, err = NewEd25519Signer("id", ed25519.PrivateKey{1})
+	require.Error(t, err)
+	_, err = NewVerifier(VerificationKey{ID: "id", Algorithm: "rsa"})
+	require.Error(t, err)
+	_, err = NewRotatingSigner(nil)
+	require.Error(t, err)
+}
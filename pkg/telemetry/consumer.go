@@ -2,6 +2,7 @@ package telemetry
 
 import (
 	"context"
+	"io"
 	"sync"
 
 	"github.com/go-logr/logr"
@@ -24,15 +25,20 @@ type Forwarder interface {
 
 // NewConsumer creates a new consumer which will use the provided serializer to
 // serialize the data and then forward it using the provided forwarder.
-func NewConsumer(s Serializer, f Forwarder) *consumer {
+func NewConsumer(s Serializer, f Forwarder, opts ...OptConsumer) *consumer {
 	var (
 		ch          = make(chan types.SignalReport)
 		ctx, cancel = context.WithCancel(context.Background())
 		// TODO: allow configuration: https://github.com/Kong/kubernetes-telemetry/issues/46
-		logger = defaultLogger()
+		logger      = defaultLogger()
+		cfg         = newConsumerConfig(opts)
+		transformer = cfg.transformer()
 	)
 
 	go func() {
+		if cfg.closeForwarder {
+			defer closeForwarder(logger, f)
+		}
 		done := ctx.Done()
 
 		for {
@@ -40,6 +46,12 @@ func NewConsumer(s Serializer, f Forwarder) *consumer {
 			case <-done:
 				return
 			case sr := <-ch:
+				sr, err := transformer.Transform(sr)
+				if err != nil {
+					logger.Error(err, "failed to transform report")
+					continue
+				}
+
 				b, err := s.Serialize(sr.Report, sr.Signal)
 				if err != nil {
 					logger.Error(err, "failed to serialize report")
@@ -65,7 +77,8 @@ func (c *consumer) Intake() chan<- types.SignalReport {
 	return c.ch
 }
 
-// Close closes the consumer.
+// Close closes the consumer. With OptConsumerCloseForwarder, the forwarder is
+// closed as well once the report being forwarded, if any, is done.
 func (c *consumer) Close() {
 	c.once.Do(func() {
 		c.cancel()
@@ -88,15 +101,20 @@ type RawForwarder interface {
 
 // NewRawConsumer creates a new rawconsumer that will use the provided raw forwarder
 // to forward received reports.
-func NewRawConsumer(f RawForwarder) *rawConsumer {
+func NewRawConsumer(f RawForwarder, opts ...OptConsumer) *rawConsumer {
 	var (
 		ch          = make(chan types.SignalReport)
 		ctx, cancel = context.WithCancel(context.Background())
 		// TODO: allow configuration: https://github.com/Kong/kubernetes-telemetry/issues/46
-		logger = defaultLogger()
+		logger      = defaultLogger()
+		cfg         = newConsumerConfig(opts)
+		transformer = cfg.transformer()
 	)
 
 	go func() {
+		if cfg.closeForwarder {
+			defer closeForwarder(logger, f)
+		}
 		done := ctx.Done()
 
 		for {
@@ -104,6 +122,12 @@ func NewRawConsumer(f RawForwarder) *rawConsumer {
 			case <-done:
 				return
 			case sr := <-ch:
+				sr, err := transformer.Transform(sr)
+				if err != nil {
+					logger.Error(err, "failed to transform report")
+					continue
+				}
+
 				if err := f.Forward(ctx, sr); err != nil {
 					logger.Error(err, "failed to forward report using raw forwarder: %s", f.Name())
 				}
@@ -123,9 +147,21 @@ func (c *rawConsumer) Intake() chan<- types.SignalReport {
 	return c.ch
 }
 
-// Close closes rawconsumer.
+// Close closes rawconsumer. With OptConsumerCloseForwarder, the raw forwarder
+// is closed as well once the report being forwarded, if any, is done.
 func (c *rawConsumer) Close() {
 	c.once.Do(func() {
 		c.cancel()
 	})
 }
+
+// closeForwarder closes the forwarder if it implements io.Closer.
+func closeForwarder(logger logr.Logger, f any) {
+	closer, ok := f.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		logger.Error(err, "failed to close forwarder")
+	}
+}
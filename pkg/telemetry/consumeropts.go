@@ -0,0 +1,42 @@
+package telemetry
+
+// consumerConfig holds configuration shared by consumers.
+type consumerConfig struct {
+	transformers   []Transformer
+	closeForwarder bool
+}
+
+// OptConsumer is the option function type that can configure consumers created
+// with NewConsumer and NewRawConsumer.
+type OptConsumer func(*consumerConfig)
+
+// OptConsumerTransformers returns an option that will make the consumer apply
+// the provided transformers, in order, to every report before consuming it.
+// Reports for which a transformer returns an error are logged and dropped.
+func OptConsumerTransformers(transformers ...Transformer) OptConsumer {
+	return func(c *consumerConfig) {
+		c.transformers = append(c.transformers, transformers...)
+	}
+}
+
+// OptConsumerCloseForwarder returns an option that will make the consumer own
+// its forwarder: when the consumer is closed, e.g. by the manager's Stop, it
+// closes the forwarder if it implements io.Closer, like a TLS forwarder keeping
+// its connection open. Don't use it when the forwarder is shared by consumers.
+func OptConsumerCloseForwarder() OptConsumer {
+	return func(c *consumerConfig) {
+		c.closeForwarder = true
+	}
+}
+
+func newConsumerConfig(opts []OptConsumer) consumerConfig {
+	var c consumerConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func (c consumerConfig) transformer() Transformer {
+	return ChainTransformers(c.transformers...)
+}
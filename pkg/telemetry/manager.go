@@ -12,6 +12,8 @@ import (
 	"github.com/puzpuzpuz/xsync/v2"
 
 	"github.com/kong/kubernetes-telemetry/pkg/log"
+	"github.com/kong/kubernetes-telemetry/pkg/redact"
+	"github.com/kong/kubernetes-telemetry/pkg/schema"
 	"github.com/kong/kubernetes-telemetry/pkg/types"
 )
 
@@ -48,6 +50,12 @@ type manager struct {
 	// For now, all workflows work on the same cadence, i.e. are triggered at the
 	// same given, ruled by one timer.
 	period time.Duration
+	// schema, when set, is used to validate reports before they are dispatched
+	// to consumers.
+	schema *schema.Schema
+	// redactor, when set, is used to redact reports before they are dispatched
+	// to consumers.
+	redactor *redact.Redactor
 
 	// consumers is a slice of channels that will consume reports produced by
 	// execution of workflows.
@@ -224,6 +232,25 @@ func (m *manager) workflowsLoop() {
 				continue
 			}
 
+			// Reports are validated before they are redacted because redaction
+			// adds the redact.RedactedKeysKey key and changes types of values,
+			// e.g. bucketized integers become strings.
+			if m.schema != nil {
+				if err := m.schema.Validate(report); err != nil {
+					m.logger.Error(err, "report does not conform to schema, not dispatching it", "signal", signal)
+					cancel()
+					continue
+				}
+			}
+
+			if m.redactor != nil {
+				if report, err = m.redactor.Redact(report); err != nil {
+					m.logger.Error(err, "failed to redact report, not dispatching it", "signal", signal)
+					cancel()
+					continue
+				}
+			}
+
 			select {
 			case m.ch <- types.SignalReport{
 				Signal: signal,
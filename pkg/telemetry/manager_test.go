@@ -23,6 +23,8 @@ import (
 
 	"github.com/kong/kubernetes-telemetry/pkg/forwarders"
 	"github.com/kong/kubernetes-telemetry/pkg/provider"
+	"github.com/kong/kubernetes-telemetry/pkg/redact"
+	"github.com/kong/kubernetes-telemetry/pkg/schema"
 	"github.com/kong/kubernetes-telemetry/pkg/serializers"
 	"github.com/kong/kubernetes-telemetry/pkg/types"
 )
@@ -34,6 +36,53 @@ func TestManagerStartStopDoesntFail(t *testing.T) {
 	m.Stop()
 }
 
+type closableForwarder struct {
+	closed chan struct{}
+}
+
+func (f closableForwarder) Name() string { return "closable" }
+
+func (f closableForwarder) Forward(context.Context, []byte) error { return nil }
+
+func (f closableForwarder) Close() error {
+	close(f.closed)
+	return nil
+}
+
+type closableRawForwarder struct {
+	closableForwarder
+}
+
+func (f closableRawForwarder) Forward(context.Context, types.SignalReport) error { return nil }
+
+func TestManagerStopClosesOwnedForwarders(t *testing.T) {
+	m, err := NewManager("dummy-signal", OptManagerLogger(logr.Discard()))
+	require.NoError(t, err)
+
+	f := closableForwarder{closed: make(chan struct{})}
+	rf := closableRawForwarder{closableForwarder{closed: make(chan struct{})}}
+	shared := closableForwarder{closed: make(chan struct{})}
+	require.NoError(t, m.AddConsumer(NewConsumer(serializers.NewSemicolonDelimited(), f, OptConsumerCloseForwarder())))
+	require.NoError(t, m.AddConsumer(NewRawConsumer(rf, OptConsumerCloseForwarder())))
+	require.NoError(t, m.AddConsumer(NewConsumer(serializers.NewSemicolonDelimited(), shared)))
+	require.NoError(t, m.AddConsumer(NewConsumer(serializers.NewSemicolonDelimited(), shared)))
+	require.NoError(t, m.Start())
+	m.Stop()
+
+	for _, closed := range []chan struct{}{f.closed, rf.closed} {
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			require.Fail(t, "owned forwarder wasn't closed when the manager was stopped")
+		}
+	}
+	select {
+	case <-shared.closed:
+		require.Fail(t, "forwarder which isn't owned by its consumers was closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
 func TestManagerBasicLogicWorks(t *testing.T) {
 	m, err := NewManager(
 		"dummy-signal",
@@ -516,3 +565,122 @@ func TestManagerNoGoroutineLeak(t *testing.T) {
 	// Stop manager.
 	m.Stop()
 }
+
+func TestManagerSchema(t *testing.T) {
+	w := NewWorkflow("basic1")
+	p, err := provider.NewFixedValueProvider("constant1", types.ProviderReport{
+		"constant1": "value1",
+	})
+	require.NoError(t, err)
+	w.AddProvider(p)
+
+	newManager := func(t *testing.T, s schema.Schema) (Manager, chan types.SignalReport) {
+		m, err := NewManager(
+			"dummy-signal",
+			OptManagerLogger(logr.Discard()),
+			OptManagerPeriod(time.Hour),
+			OptManagerSchema(s),
+		)
+		require.NoError(t, err)
+		m.AddWorkflow(w)
+
+		ch := make(chan types.SignalReport)
+		require.NoError(t, m.AddConsumer(NewRawConsumer(forwarders.NewRawChannelForwarder(ch))))
+		require.NoError(t, m.Start())
+		t.Cleanup(m.Stop)
+		return m, ch
+	}
+
+	t.Run("conformant reports are dispatched", func(t *testing.T) {
+		m, ch := newManager(t, schema.Schema{
+			Workflows: []schema.Workflow{
+				{
+					Name: "basic1",
+					Keys: []schema.Key{
+						{Key: "constant1", Type: types.ValueTypeString, Required: true},
+					},
+				},
+			},
+		})
+
+		require.NoError(t, m.TriggerExecute(context.Background(), "test-signal"))
+		select {
+		case r := <-ch:
+			require.Equal(t, types.Signal("test-signal"), r.Signal)
+		case <-time.After(time.Second):
+			require.Fail(t, "timed out waiting for report")
+		}
+	})
+
+	t.Run("non conformant reports are not dispatched", func(t *testing.T) {
+		m, ch := newManager(t, schema.Schema{
+			Workflows: []schema.Workflow{
+				{
+					Name: "basic1",
+					Keys: []schema.Key{
+						{Key: "constant1", Type: types.ValueTypeInt, Required: true},
+					},
+				},
+			},
+		})
+
+		require.NoError(t, m.TriggerExecute(context.Background(), "test-signal"))
+		select {
+		case r := <-ch:
+			require.Failf(t, "non conformant report dispatched", "%v", r)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
+
+func TestManagerSchemaWithRedactor(t *testing.T) {
+	w := NewWorkflow("basic1")
+	p, err := provider.NewFixedValueProvider("constant1", types.ProviderReport{
+		"hostname": "node-1",
+		"nodes":    42,
+	})
+	require.NoError(t, err)
+	w.AddProvider(p)
+
+	r, err := redact.NewRedactor([]byte("salt"),
+		redact.Rule{Key: "hostname", Action: redact.ActionHash},
+		redact.Rule{Key: "nodes", Action: redact.ActionBucketize, Buckets: []float64{10, 100}},
+	)
+	require.NoError(t, err)
+
+	m, err := NewManager(
+		"dummy-signal",
+		OptManagerLogger(logr.Discard()),
+		OptManagerPeriod(time.Hour),
+		OptManagerRedactor(r),
+		OptManagerSchema(schema.Schema{
+			Workflows: []schema.Workflow{
+				{
+					Name: "basic1",
+					Keys: []schema.Key{
+						{Key: "hostname", Type: types.ValueTypeString, Required: true},
+						{Key: "nodes", Type: types.ValueTypeInt, Required: true},
+					},
+				},
+			},
+		}),
+	)
+	require.NoError(t, err)
+	m.AddWorkflow(w)
+
+	ch := make(chan types.SignalReport)
+	require.NoError(t, m.AddConsumer(NewRawConsumer(forwarders.NewRawChannelForwarder(ch))))
+	require.NoError(t, m.Start())
+	t.Cleanup(m.Stop)
+
+	require.NoError(t, m.TriggerExecute(context.Background(), "test-signal"))
+	select {
+	case sr := <-ch:
+		report := sr.Report["basic1"]
+		require.Equal(t, "10-100", report["nodes"])
+		require.NotEqual(t, "node-1", report["hostname"])
+		require.Contains(t, report, redact.RedactedKeysKey)
+	case <-time.After(time.Second):
+		require.Fail(t, "timed out waiting for report")
+	}
+}
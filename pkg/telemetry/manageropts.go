@@ -1,9 +1,13 @@
 package telemetry
 
 import (
+	"errors"
 	"time"
 
 	"github.com/go-logr/logr"
+
+	"github.com/kong/kubernetes-telemetry/pkg/redact"
+	"github.com/kong/kubernetes-telemetry/pkg/schema"
 )
 
 // OptManager is the option function type that can configure the manager.
@@ -24,3 +28,30 @@ func OptManagerPeriod(period time.Duration) OptManager {
 		return nil
 	}
 }
+
+// OptManagerSchema returns an option that will make the manager validate every
+// report against the provided schema before dispatching it to consumers.
+// Reports that do not conform to the schema are logged and not dispatched.
+// Reports are validated before they are redacted with the redactor set with
+// OptManagerRedactor, so the schema describes reports as returned by workflows.
+func OptManagerSchema(s schema.Schema) OptManager {
+	return func(m *manager) error {
+		m.schema = &s
+		return nil
+	}
+}
+
+// OptManagerRedactor returns an option that will make the manager redact every
+// report with the provided redactor before dispatching it to consumers. When
+// a schema is configured as well, reports are validated against it before they
+// are redacted, see OptManagerSchema. Reports which fail to be redacted are
+// logged and not dispatched.
+func OptManagerRedactor(r *redact.Redactor) OptManager {
+	return func(m *manager) error {
+		if r == nil {
+			return errors.New("redactor cannot be nil")
+		}
+		m.redactor = r
+		return nil
+	}
+}
@@ -0,0 +1,265 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+const (
+	// PrometheusContentType is the content type of the Prometheus text
+	// exposition format served by the Prometheus consumer.
+	PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	prometheusInfoSuffix     = "_info"
+	prometheusDurationSuffix = "_seconds"
+)
+
+type prometheusConsumer struct {
+	logger    logr.Logger
+	once      sync.Once
+	ch        chan types.SignalReport
+	cancel    func()
+	namespace string
+	// exposition holds the latest report rendered in the text exposition format.
+	exposition atomic.Pointer[[]byte]
+}
+
+// NewPrometheusConsumer creates a new consumer which keeps the latest received
+// report and serves it in the Prometheus text exposition format. The consumer
+// is an http.Handler which can be registered e.g. under "/metrics" and it's
+// safe to be scraped concurrently.
+//
+// Numeric values become gauges named "<namespace>_<workflow>_<key>" (keys of
+// nested reports are joined with an underscore and the namespace is omitted
+// when empty). Durations are exposed in seconds with a "_seconds" suffix and
+// booleans as 0 or 1. All other values of a workflow become labels of a single
+// "<namespace>_<workflow>_info" metric with value 1. Names are sanitized to
+// match the Prometheus data model.
+func NewPrometheusConsumer(namespace string, opts ...OptConsumer) *prometheusConsumer {
+	var (
+		ch          = make(chan types.SignalReport)
+		ctx, cancel = context.WithCancel(context.Background())
+		transformer = newConsumerConfig(opts).transformer()
+	)
+
+	c := &prometheusConsumer{
+		// TODO: allow configuration: https://github.com/Kong/kubernetes-telemetry/issues/46
+		logger:    defaultLogger(),
+		ch:        ch,
+		cancel:    cancel,
+		namespace: namespace,
+	}
+
+	go func() {
+		done := ctx.Done()
+
+		for {
+			select {
+			case <-done:
+				return
+			case sr := <-ch:
+				sr, err := transformer.Transform(sr)
+				if err != nil {
+					c.logger.Error(err, "failed to transform report")
+					continue
+				}
+
+				b, err := renderPrometheus(c.namespace, sr.Report)
+				if err != nil {
+					c.logger.Error(err, "failed to render report in Prometheus exposition format")
+					continue
+				}
+				c.exposition.Store(&b)
+			}
+		}
+	}()
+
+	return c
+}
+
+// Intake returns a channel on which this consumer will wait for data to consume it.
+func (c *prometheusConsumer) Intake() chan<- types.SignalReport {
+	return c.ch
+}
+
+// Close closes the consumer.
+func (c *prometheusConsumer) Close() {
+	c.once.Do(func() {
+		c.cancel()
+	})
+}
+
+// ServeHTTP serves the latest report in the Prometheus text exposition format.
+// Until the first report is received the response is empty.
+func (c *prometheusConsumer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", PrometheusContentType)
+	if b := c.exposition.Load(); b != nil {
+		_, _ = w.Write(*b)
+	}
+}
+
+type prometheusSample struct {
+	name  string
+	value float64
+}
+
+type prometheusLabel struct {
+	name  string
+	value string
+}
+
+func renderPrometheus(namespace string, report types.Report) ([]byte, error) {
+	var (
+		buf  bytes.Buffer
+		seen = map[string]struct{}{}
+	)
+	for _, workflow := range report.Names() {
+		var (
+			prefix  = prometheusName(joinNonEmpty("_", namespace, workflow))
+			samples []prometheusSample
+			labels  []prometheusLabel
+		)
+		err := walkPrometheus(report[workflow], "", func(key string, v any, t types.ValueType) {
+			switch t { //nolint:exhaustive
+			case types.ValueTypeInt:
+				samples = append(samples, prometheusSample{key, float64(v.(int64))})
+			case types.ValueTypeFloat:
+				samples = append(samples, prometheusSample{key, v.(float64)})
+			case types.ValueTypeBool:
+				var f float64
+				if v.(bool) {
+					f = 1
+				}
+				samples = append(samples, prometheusSample{key, f})
+			case types.ValueTypeDuration:
+				samples = append(samples, prometheusSample{key + prometheusDurationSuffix, v.(time.Duration).Seconds()})
+			case types.ValueTypeString:
+				labels = append(labels, prometheusLabel{key, v.(string)})
+			case types.ValueTypeStringList:
+				labels = append(labels, prometheusLabel{key, strings.Join(v.([]string), ",")})
+			case types.ValueTypeTime:
+				labels = append(labels, prometheusLabel{key, v.(time.Time).UTC().Format(time.RFC3339Nano)})
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("workflow %s: %w", workflow, err)
+		}
+
+		for _, s := range samples {
+			name := prefix + "_" + prometheusName(s.name)
+			// Different keys can be sanitized into the same name, keep the first one.
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			fmt.Fprintf(&buf, "# TYPE %s gauge\n%s %s\n", name, name, strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+
+		if len(labels) > 0 {
+			name := prefix + prometheusInfoSuffix
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+
+			var (
+				pairs      = make([]string, 0, len(labels))
+				seenLabels = map[string]struct{}{}
+			)
+			for _, l := range labels {
+				ln := prometheusLabelName(l.name)
+				if _, ok := seenLabels[ln]; ok {
+					continue
+				}
+				seenLabels[ln] = struct{}{}
+				pairs = append(pairs, ln+`="`+escapePrometheusLabelValue(l.value)+`"`)
+			}
+			fmt.Fprintf(&buf, "# TYPE %s gauge\n%s{%s} 1\n", name, name, strings.Join(pairs, ","))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// walkPrometheus calls f for every value in the report, descending into nested
+// reports, in key order.
+func walkPrometheus(report types.ProviderReport, prefix string, f func(string, any, types.ValueType)) error {
+	for _, k := range report.Keys() {
+		key := joinNonEmpty("_", prefix, string(k))
+		v, t, err := types.CanonicalValue(report[k])
+		if err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+		if t == types.ValueTypeReport {
+			if err := walkPrometheus(v.(types.ProviderReport), key, f); err != nil {
+				return err
+			}
+			continue
+		}
+		f(key, v, t)
+	}
+	return nil
+}
+
+// prometheusName sanitizes s to match [a-zA-Z_:][a-zA-Z0-9_:]*.
+func prometheusName(s string) string {
+	return sanitizePrometheus(s, true)
+}
+
+// prometheusLabelName sanitizes s to match [a-zA-Z_][a-zA-Z0-9_]*.
+func prometheusLabelName(s string) string {
+	return sanitizePrometheus(s, false)
+}
+
+func sanitizePrometheus(s string, allowColon bool) string {
+	b := []byte(s)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case c >= '0' && c <= '9' && i > 0:
+		case c == ':' && allowColon:
+		default:
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+func escapePrometheusLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
+
+// Ensure the Prometheus consumer can be used as a consumer and a handler.
+var (
+	_ Consumer     = (*prometheusConsumer)(nil)
+	_ http.Handler = (*prometheusConsumer)(nil)
+)
@@ -0,0 +1,106 @@
+package telemetry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestRenderPrometheus(t *testing.T) {
+	report := types.Report{
+		"identify-platform": types.ProviderReport{
+			"k8sv":           "v1.27.1",
+			"k8s_pods_count": 7,
+			"k8s-pods-count": 8,
+			"archs":          []string{"amd64", "arm64"},
+			"quote":          "a\"b\\c\nd",
+		},
+		"state": types.ProviderReport{
+			"uptime": 90 * time.Second,
+			"ok":     true,
+			"ratio":  0.5,
+			"nested": types.ProviderReport{
+				"n": uint8(3),
+			},
+		},
+	}
+
+	b, err := renderPrometheus("kic", report)
+	require.NoError(t, err)
+	assert.Equal(t, `# TYPE kic_identify_platform_k8s_pods_count gauge
+kic_identify_platform_k8s_pods_count 8
+# TYPE kic_identify_platform_info gauge
+kic_identify_platform_info{archs="amd64,arm64",k8sv="v1.27.1",quote="a\"b\\c\nd"} 1
+# TYPE kic_state_nested_n gauge
+kic_state_nested_n 3
+# TYPE kic_state_ok gauge
+kic_state_ok 1
+# TYPE kic_state_ratio gauge
+kic_state_ratio 0.5
+# TYPE kic_state_uptime_seconds gauge
+kic_state_uptime_seconds 90
+`, string(b))
+
+	_, err = renderPrometheus("", types.Report{"w": types.ProviderReport{"k": struct{}{}}})
+	require.Error(t, err)
+}
+
+func TestPrometheusConsumer(t *testing.T) {
+	c := NewPrometheusConsumer("")
+	t.Cleanup(c.Close)
+	srv := httptest.NewServer(c)
+	t.Cleanup(srv.Close)
+
+	scrape := func() string {
+		resp, err := http.Get(srv.URL) //nolint:noctx
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, PrometheusContentType, resp.Header.Get("Content-Type"))
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	assert.Empty(t, scrape(), "nothing should be served before the first report")
+
+	c.Intake() <- types.SignalReport{
+		Signal: "test",
+		Report: types.Report{"w": types.ProviderReport{"count": 1}},
+	}
+	require.Eventually(t, func() bool {
+		return scrape() == "# TYPE w_count gauge\nw_count 1\n"
+	}, time.Second, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			for range 10 {
+				_ = scrape()
+			}
+		})
+	}
+	for i := range 10 {
+		c.Intake() <- types.SignalReport{
+			Signal: "test",
+			Report: types.Report{"w": types.ProviderReport{"count": i}},
+		}
+	}
+	wg.Wait()
+	require.Eventually(t, func() bool {
+		return scrape() == "# TYPE w_count gauge\nw_count 9\n"
+	}, time.Second, time.Millisecond)
+
+	resp, err := http.Post(srv.URL, "text/plain", nil) //nolint:noctx
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
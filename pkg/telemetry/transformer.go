@@ -0,0 +1,36 @@
+package telemetry
+
+import (
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// Transformer transforms reports before they are serialized and forwarded by
+// a consumer.
+//
+// The same report is delivered to all consumers so implementations must not
+// modify the report they receive. They should return a modified copy instead.
+type Transformer interface {
+	Transform(types.SignalReport) (types.SignalReport, error)
+}
+
+// TransformerFunc is an adapter allowing to use ordinary functions as Transformers.
+type TransformerFunc func(types.SignalReport) (types.SignalReport, error)
+
+// Transform calls f(sr).
+func (f TransformerFunc) Transform(sr types.SignalReport) (types.SignalReport, error) {
+	return f(sr)
+}
+
+// ChainTransformers returns a Transformer which applies the provided transformers
+// in order, passing the output of one as the input of the next one.
+func ChainTransformers(transformers ...Transformer) Transformer {
+	return TransformerFunc(func(sr types.SignalReport) (types.SignalReport, error) {
+		for _, t := range transformers {
+			var err error
+			if sr, err = t.Transform(sr); err != nil {
+				return types.SignalReport{}, err
+			}
+		}
+		return sr, nil
+	})
+}
@@ -20,7 +20,9 @@ type Workflow interface {
 	Name() string
 	// AddProvider adds a provider.
 	AddProvider(provider.Provider)
-	// Execute executes the workflow.
+	// Execute executes the workflow. Reports of providers which contain values
+	// of unsupported types (see types.ValueTypeOf) are omitted and an error is
+	// returned for them.
 	Execute(context.Context) (types.ProviderReport, error)
 }
 
@@ -79,6 +81,12 @@ func (w *workflow) Execute(ctx context.Context) (types.ProviderReport, error) {
 			if err != nil {
 				chErr <- fmt.Errorf("problem with provider %s: %w", p.Name(), err)
 			}
+			// Reject reports with unsupported values so that serializers downstream
+			// don't have to guess how to format them.
+			if err := report.Validate(); err != nil {
+				chErr <- fmt.Errorf("provider %s reported unsupported values: %w", p.Name(), err)
+				report = nil
+			}
 
 			chReport <- report
 		})
@@ -36,3 +36,30 @@ func TestWorkflow(t *testing.T) {
 		"constant2": "value2",
 	}, report)
 }
+
+func TestWorkflowUnsupportedValues(t *testing.T) {
+	w := NewWorkflow("test1")
+
+	{
+		p, err := provider.NewFixedValueProvider("constant1", types.ProviderReport{
+			"constant1": "value1",
+		})
+		require.NoError(t, err)
+		w.AddProvider(p)
+	}
+	{
+		p, err := provider.NewFixedValueProvider("invalid", types.ProviderReport{
+			"constant2": "value2",
+			"invalid":   make(chan int),
+		})
+		require.NoError(t, err)
+		w.AddProvider(p)
+	}
+
+	report, err := w.Execute(context.Background())
+	require.ErrorAs(t, err, &types.ErrUnsupportedValue{})
+
+	require.EqualValues(t, types.ProviderReport{
+		"constant1": "value1",
+	}, report)
+}
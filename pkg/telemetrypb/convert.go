@@ -0,0 +1,196 @@
+// Package telemetrypb contains the Protobuf wire format of telemetry reports
+// defined in report.proto along with helpers which convert between the
+// generated types and types.SignalReport, so that clients and servers can share
+// one contract.
+package telemetrypb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// SchemaVersion is the version of report.proto implemented by this package.
+// It's bumped whenever the schema changes in a way that consumers need to be
+// aware of.
+const SchemaVersion uint32 = 1
+
+// FromSignalReport converts the signal report into its wire representation.
+// Workflows and keys are sorted so that the encoding is deterministic.
+// The returned report's envelope only has the schema version set.
+func FromSignalReport(sr types.SignalReport) (*SignalReport, error) {
+	workflows, err := FromReport(sr.Report)
+	if err != nil {
+		return nil, err
+	}
+	return &SignalReport{
+		Envelope: &Envelope{
+			SchemaVersion: SchemaVersion,
+		},
+		Signal:    string(sr.Signal),
+		Workflows: workflows,
+	}, nil
+}
+
+// FromReport converts the report into wire representations of its workflows'
+// reports, sorted by workflow name.
+func FromReport(report types.Report) ([]*WorkflowReport, error) {
+	out := make([]*WorkflowReport, 0, len(report))
+	for _, name := range report.Names() {
+		r, err := FromProviderReport(report[name])
+		if err != nil {
+			return nil, fmt.Errorf("workflow %s: %w", name, err)
+		}
+		out = append(out, &WorkflowReport{
+			Name:   name,
+			Report: r,
+		})
+	}
+	return out, nil
+}
+
+// FromProviderReport converts the provider report into its wire representation
+// with entries sorted by key.
+func FromProviderReport(report types.ProviderReport) (*Report, error) {
+	out := &Report{
+		Entries: make([]*Entry, 0, len(report)),
+	}
+	for _, k := range report.Keys() {
+		v, err := FromValue(report[k])
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", k, err)
+		}
+		out.Entries = append(out.Entries, &Entry{
+			Key:   string(k),
+			Value: v,
+		})
+	}
+	return out, nil
+}
+
+// FromValue converts a report value into its wire representation. It returns
+// a types.ErrUnsupportedValue for values of unsupported types.
+func FromValue(v any) (*Value, error) {
+	cv, t, err := types.CanonicalValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case types.ValueTypeString:
+		return &Value{Kind: &Value_StringValue{StringValue: cv.(string)}}, nil
+	case types.ValueTypeInt:
+		return &Value{Kind: &Value_IntValue{IntValue: cv.(int64)}}, nil
+	case types.ValueTypeFloat:
+		return &Value{Kind: &Value_FloatValue{FloatValue: cv.(float64)}}, nil
+	case types.ValueTypeBool:
+		return &Value{Kind: &Value_BoolValue{BoolValue: cv.(bool)}}, nil
+	case types.ValueTypeDuration:
+		return &Value{Kind: &Value_DurationValue{DurationValue: durationpb.New(cv.(time.Duration))}}, nil
+	case types.ValueTypeTime:
+		return &Value{Kind: &Value_TimeValue{TimeValue: timestamppb.New(cv.(time.Time))}}, nil
+	case types.ValueTypeStringList:
+		return &Value{Kind: &Value_StringListValue{StringListValue: &StringList{Values: cv.([]string)}}}, nil
+	case types.ValueTypeReport:
+		r, err := FromProviderReport(cv.(types.ProviderReport))
+		if err != nil {
+			return nil, err
+		}
+		return &Value{Kind: &Value_ReportValue{ReportValue: r}}, nil
+	}
+	return nil, fmt.Errorf("unknown value type %q", t)
+}
+
+// ToSignalReport converts the wire representation back into a signal report.
+// Values are restored using the canonical Go types of their types.ValueType,
+// e.g. all integers are restored as int64.
+//
+// Reports encoded with a newer, unknown schema version are rejected.
+func ToSignalReport(sr *SignalReport) (types.SignalReport, error) {
+	if v := sr.GetEnvelope().GetSchemaVersion(); v > SchemaVersion {
+		return types.SignalReport{}, fmt.Errorf("unsupported schema version %d, supported up to %d", v, SchemaVersion)
+	}
+	report, err := ToReport(sr.GetWorkflows())
+	if err != nil {
+		return types.SignalReport{}, err
+	}
+	return types.SignalReport{
+		Signal: types.Signal(sr.GetSignal()),
+		Report: report,
+	}, nil
+}
+
+// ToReport converts wire representations of workflows' reports back into
+// a report.
+func ToReport(workflows []*WorkflowReport) (types.Report, error) {
+	out := make(types.Report, len(workflows))
+	for _, w := range workflows {
+		if _, ok := out[w.GetName()]; ok {
+			return nil, fmt.Errorf("duplicate workflow %s", w.GetName())
+		}
+		r, err := ToProviderReport(w.GetReport())
+		if err != nil {
+			return nil, fmt.Errorf("workflow %s: %w", w.GetName(), err)
+		}
+		out[w.GetName()] = r
+	}
+	return out, nil
+}
+
+// ToProviderReport converts the wire representation back into a provider report.
+func ToProviderReport(report *Report) (types.ProviderReport, error) {
+	out := make(types.ProviderReport, len(report.GetEntries()))
+	for _, e := range report.GetEntries() {
+		k := types.ProviderReportKey(e.GetKey())
+		if _, ok := out[k]; ok {
+			return nil, fmt.Errorf("duplicate key %s", k)
+		}
+		v, err := ToValue(e.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", k, err)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// ToValue converts the wire representation of a value back into a report value.
+func ToValue(v *Value) (any, error) {
+	switch kind := v.GetKind().(type) {
+	case *Value_StringValue:
+		return kind.StringValue, nil
+	case *Value_IntValue:
+		return kind.IntValue, nil
+	case *Value_FloatValue:
+		return kind.FloatValue, nil
+	case *Value_BoolValue:
+		return kind.BoolValue, nil
+	case *Value_DurationValue:
+		if err := kind.DurationValue.CheckValid(); err != nil {
+			return nil, err
+		}
+		return kind.DurationValue.AsDuration(), nil
+	case *Value_TimeValue:
+		if err := kind.TimeValue.CheckValid(); err != nil {
+			return nil, err
+		}
+		return kind.TimeValue.AsTime(), nil
+	case *Value_StringListValue:
+		l := kind.StringListValue.GetValues()
+		if l == nil {
+			l = []string{}
+		}
+		return l, nil
+	case *Value_ReportValue:
+		return ToProviderReport(kind.ReportValue)
+	case nil:
+		return nil, errors.New("value is not set")
+	default:
+		return nil, fmt.Errorf("unknown value kind %T", kind)
+	}
+}
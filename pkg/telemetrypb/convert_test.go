@@ -0,0 +1,102 @@
+package telemetrypb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/kong/kubernetes-telemetry/pkg/provider"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func TestConversion(t *testing.T) {
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 6, time.UTC)
+	sr := types.SignalReport{
+		Signal: "kic-ping",
+		Report: types.Report{
+			"identify-platform": types.ProviderReport{
+				"k8sv":         "v1.27.1",
+				"k8s_provider": provider.ClusterProviderGKE,
+			},
+			"state": types.ProviderReport{
+				"uptime": 10,
+				"b":      true,
+				"d":      90 * time.Second,
+				"f":      1.5,
+				"l":      []string{"a", "b"},
+				"ts":     ts,
+				"nested": types.ProviderReport{
+					"a": uint8(1),
+				},
+			},
+		},
+	}
+
+	pb, err := FromSignalReport(sr)
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion, pb.GetEnvelope().GetSchemaVersion())
+	require.Len(t, pb.GetWorkflows(), 2)
+	assert.Equal(t, "identify-platform", pb.GetWorkflows()[0].GetName())
+	assert.Equal(t, "k8s_provider", pb.GetWorkflows()[0].GetReport().GetEntries()[0].GetKey())
+
+	b, err := proto.Marshal(pb)
+	require.NoError(t, err)
+	var decoded SignalReport
+	require.NoError(t, proto.Unmarshal(b, &decoded))
+
+	out, err := ToSignalReport(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, types.SignalReport{
+		Signal: "kic-ping",
+		Report: types.Report{
+			"identify-platform": types.ProviderReport{
+				"k8sv":         "v1.27.1",
+				"k8s_provider": "GKE",
+			},
+			"state": types.ProviderReport{
+				"uptime": int64(10),
+				"b":      true,
+				"d":      90 * time.Second,
+				"f":      1.5,
+				"l":      []string{"a", "b"},
+				"ts":     ts,
+				"nested": types.ProviderReport{
+					"a": int64(1),
+				},
+			},
+		},
+	}, out)
+}
+
+func TestConversionErrors(t *testing.T) {
+	t.Run("unsupported value", func(t *testing.T) {
+		_, err := FromSignalReport(types.SignalReport{
+			Report: types.Report{"w": types.ProviderReport{"k": struct{}{}}},
+		})
+		var unsupported types.ErrUnsupportedValue
+		require.ErrorAs(t, err, &unsupported)
+	})
+
+	t.Run("newer schema version", func(t *testing.T) {
+		_, err := ToSignalReport(&SignalReport{Envelope: &Envelope{SchemaVersion: SchemaVersion + 1}})
+		require.Error(t, err)
+	})
+
+	t.Run("value not set", func(t *testing.T) {
+		_, err := ToSignalReport(&SignalReport{
+			Workflows: []*WorkflowReport{{Name: "w", Report: &Report{Entries: []*Entry{{Key: "k"}}}}},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("duplicate key", func(t *testing.T) {
+		v := &Value{Kind: &Value_BoolValue{BoolValue: true}}
+		_, err := ToSignalReport(&SignalReport{
+			Workflows: []*WorkflowReport{{Name: "w", Report: &Report{Entries: []*Entry{{Key: "k", Value: v}, {Key: "k", Value: v}}}}},
+		})
+		require.Error(t, err)
+	})
+}
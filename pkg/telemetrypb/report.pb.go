@@ -0,0 +1,644 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11-devel
+// 	protoc        (unknown)
+// source: pkg/telemetrypb/report.proto
+
+package telemetrypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SignalReport is a telemetry report sent with a signal.
+type SignalReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Envelope holds metadata about the report.
+	Envelope *Envelope `protobuf:"bytes,1,opt,name=envelope,proto3" json:"envelope,omitempty"`
+	// Signal is the signal the report was sent with, e.g. "kic-ping".
+	Signal string `protobuf:"bytes,2,opt,name=signal,proto3" json:"signal,omitempty"`
+	// Workflows holds reports of all workflows, sorted by name.
+	Workflows     []*WorkflowReport `protobuf:"bytes,3,rep,name=workflows,proto3" json:"workflows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalReport) Reset() {
+	*x = SignalReport{}
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalReport) ProtoMessage() {}
+
+func (x *SignalReport) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalReport.ProtoReflect.Descriptor instead.
+func (*SignalReport) Descriptor() ([]byte, []int) {
+	return file_pkg_telemetrypb_report_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SignalReport) GetEnvelope() *Envelope {
+	if x != nil {
+		return x.Envelope
+	}
+	return nil
+}
+
+func (x *SignalReport) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *SignalReport) GetWorkflows() []*WorkflowReport {
+	if x != nil {
+		return x.Workflows
+	}
+	return nil
+}
+
+// Envelope holds metadata about a report.
+type Envelope struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// SchemaVersion is the version of this schema the report was encoded with.
+	SchemaVersion uint32 `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// CreatedAt is the time the report was serialized.
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Producer identifies the software that produced the report, e.g. "kic/3.0.0".
+	Producer      string `protobuf:"bytes,3,opt,name=producer,proto3" json:"producer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_pkg_telemetrypb_report_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Envelope) GetSchemaVersion() uint32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *Envelope) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Envelope) GetProducer() string {
+	if x != nil {
+		return x.Producer
+	}
+	return ""
+}
+
+// WorkflowReport is a report produced by a single workflow.
+type WorkflowReport struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name is the name of the workflow.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Report holds the workflow's report.
+	Report        *Report `protobuf:"bytes,2,opt,name=report,proto3" json:"report,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkflowReport) Reset() {
+	*x = WorkflowReport{}
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkflowReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkflowReport) ProtoMessage() {}
+
+func (x *WorkflowReport) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkflowReport.ProtoReflect.Descriptor instead.
+func (*WorkflowReport) Descriptor() ([]byte, []int) {
+	return file_pkg_telemetrypb_report_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WorkflowReport) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *WorkflowReport) GetReport() *Report {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+// Report holds report entries sorted by key.
+type Report struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*Entry               `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Report) Reset() {
+	*x = Report{}
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Report) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Report) ProtoMessage() {}
+
+func (x *Report) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Report.ProtoReflect.Descriptor instead.
+func (*Report) Descriptor() ([]byte, []int) {
+	return file_pkg_telemetrypb_report_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Report) GetEntries() []*Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// Entry is a single report key and its value.
+type Entry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         *Value                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_pkg_telemetrypb_report_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Entry) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Entry) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// Value is a typed report value.
+type Value struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*Value_StringValue
+	//	*Value_IntValue
+	//	*Value_FloatValue
+	//	*Value_BoolValue
+	//	*Value_DurationValue
+	//	*Value_TimeValue
+	//	*Value_StringListValue
+	//	*Value_ReportValue
+	Kind          isValue_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_pkg_telemetrypb_report_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Value) GetKind() isValue_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *Value) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *Value) GetIntValue() int64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetFloatValue() float64 {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_FloatValue); ok {
+			return x.FloatValue
+		}
+	}
+	return 0
+}
+
+func (x *Value) GetBoolValue() bool {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_BoolValue); ok {
+			return x.BoolValue
+		}
+	}
+	return false
+}
+
+func (x *Value) GetDurationValue() *durationpb.Duration {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_DurationValue); ok {
+			return x.DurationValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetTimeValue() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_TimeValue); ok {
+			return x.TimeValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetStringListValue() *StringList {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_StringListValue); ok {
+			return x.StringListValue
+		}
+	}
+	return nil
+}
+
+func (x *Value) GetReportValue() *Report {
+	if x != nil {
+		if x, ok := x.Kind.(*Value_ReportValue); ok {
+			return x.ReportValue
+		}
+	}
+	return nil
+}
+
+type isValue_Kind interface {
+	isValue_Kind()
+}
+
+type Value_StringValue struct {
+	StringValue string `protobuf:"bytes,1,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Value_IntValue struct {
+	IntValue int64 `protobuf:"varint,2,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type Value_FloatValue struct {
+	FloatValue float64 `protobuf:"fixed64,3,opt,name=float_value,json=floatValue,proto3,oneof"`
+}
+
+type Value_BoolValue struct {
+	BoolValue bool `protobuf:"varint,4,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type Value_DurationValue struct {
+	DurationValue *durationpb.Duration `protobuf:"bytes,5,opt,name=duration_value,json=durationValue,proto3,oneof"`
+}
+
+type Value_TimeValue struct {
+	TimeValue *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=time_value,json=timeValue,proto3,oneof"`
+}
+
+type Value_StringListValue struct {
+	StringListValue *StringList `protobuf:"bytes,7,opt,name=string_list_value,json=stringListValue,proto3,oneof"`
+}
+
+type Value_ReportValue struct {
+	ReportValue *Report `protobuf:"bytes,8,opt,name=report_value,json=reportValue,proto3,oneof"`
+}
+
+func (*Value_StringValue) isValue_Kind() {}
+
+func (*Value_IntValue) isValue_Kind() {}
+
+func (*Value_FloatValue) isValue_Kind() {}
+
+func (*Value_BoolValue) isValue_Kind() {}
+
+func (*Value_DurationValue) isValue_Kind() {}
+
+func (*Value_TimeValue) isValue_Kind() {}
+
+func (*Value_StringListValue) isValue_Kind() {}
+
+func (*Value_ReportValue) isValue_Kind() {}
+
+// StringList is a list of strings.
+type StringList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StringList) Reset() {
+	*x = StringList{}
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StringList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StringList) ProtoMessage() {}
+
+func (x *StringList) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_telemetrypb_report_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StringList.ProtoReflect.Descriptor instead.
+func (*StringList) Descriptor() ([]byte, []int) {
+	return file_pkg_telemetrypb_report_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StringList) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+var File_pkg_telemetrypb_report_proto protoreflect.FileDescriptor
+
+const file_pkg_telemetrypb_report_proto_rawDesc = "" +
+	"\n" +
+	"\x1cpkg/telemetrypb/report.proto\x12\x11kong.telemetry.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa0\x01\n" +
+	"\fSignalReport\x127\n" +
+	"\benvelope\x18\x01 \x01(\v2\x1b.kong.telemetry.v1.EnvelopeR\benvelope\x12\x16\n" +
+	"\x06signal\x18\x02 \x01(\tR\x06signal\x12?\n" +
+	"\tworkflows\x18\x03 \x03(\v2!.kong.telemetry.v1.WorkflowReportR\tworkflows\"\x88\x01\n" +
+	"\bEnvelope\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\rR\rschemaVersion\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1a\n" +
+	"\bproducer\x18\x03 \x01(\tR\bproducer\"W\n" +
+	"\x0eWorkflowReport\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x121\n" +
+	"\x06report\x18\x02 \x01(\v2\x19.kong.telemetry.v1.ReportR\x06report\"<\n" +
+	"\x06Report\x122\n" +
+	"\aentries\x18\x01 \x03(\v2\x18.kong.telemetry.v1.EntryR\aentries\"I\n" +
+	"\x05Entry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
+	"\x05value\x18\x02 \x01(\v2\x18.kong.telemetry.v1.ValueR\x05value\"\xa5\x03\n" +
+	"\x05Value\x12#\n" +
+	"\fstring_value\x18\x01 \x01(\tH\x00R\vstringValue\x12\x1d\n" +
+	"\tint_value\x18\x02 \x01(\x03H\x00R\bintValue\x12!\n" +
+	"\vfloat_value\x18\x03 \x01(\x01H\x00R\n" +
+	"floatValue\x12\x1f\n" +
+	"\n" +
+	"bool_value\x18\x04 \x01(\bH\x00R\tboolValue\x12B\n" +
+	"\x0eduration_value\x18\x05 \x01(\v2\x19.google.protobuf.DurationH\x00R\rdurationValue\x12;\n" +
+	"\n" +
+	"time_value\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\ttimeValue\x12K\n" +
+	"\x11string_list_value\x18\a \x01(\v2\x1d.kong.telemetry.v1.StringListH\x00R\x0fstringListValue\x12>\n" +
+	"\freport_value\x18\b \x01(\v2\x19.kong.telemetry.v1.ReportH\x00R\vreportValueB\x06\n" +
+	"\x04kind\"$\n" +
+	"\n" +
+	"StringList\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06valuesB6Z4github.com/kong/kubernetes-telemetry/pkg/telemetrypbb\x06proto3"
+
+var (
+	file_pkg_telemetrypb_report_proto_rawDescOnce sync.Once
+	file_pkg_telemetrypb_report_proto_rawDescData []byte
+)
+
+func file_pkg_telemetrypb_report_proto_rawDescGZIP() []byte {
+	file_pkg_telemetrypb_report_proto_rawDescOnce.Do(func() {
+		file_pkg_telemetrypb_report_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_telemetrypb_report_proto_rawDesc), len(file_pkg_telemetrypb_report_proto_rawDesc)))
+	})
+	return file_pkg_telemetrypb_report_proto_rawDescData
+}
+
+var file_pkg_telemetrypb_report_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_pkg_telemetrypb_report_proto_goTypes = []any{
+	(*SignalReport)(nil),          // 0: kong.telemetry.v1.SignalReport
+	(*Envelope)(nil),              // 1: kong.telemetry.v1.Envelope
+	(*WorkflowReport)(nil),        // 2: kong.telemetry.v1.WorkflowReport
+	(*Report)(nil),                // 3: kong.telemetry.v1.Report
+	(*Entry)(nil),                 // 4: kong.telemetry.v1.Entry
+	(*Value)(nil),                 // 5: kong.telemetry.v1.Value
+	(*StringList)(nil),            // 6: kong.telemetry.v1.StringList
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 8: google.protobuf.Duration
+}
+var file_pkg_telemetrypb_report_proto_depIdxs = []int32{
+	1,  // 0: kong.telemetry.v1.SignalReport.envelope:type_name -> kong.telemetry.v1.Envelope
+	2,  // 1: kong.telemetry.v1.SignalReport.workflows:type_name -> kong.telemetry.v1.WorkflowReport
+	7,  // 2: kong.telemetry.v1.Envelope.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 3: kong.telemetry.v1.WorkflowReport.report:type_name -> kong.telemetry.v1.Report
+	4,  // 4: kong.telemetry.v1.Report.entries:type_name -> kong.telemetry.v1.Entry
+	5,  // 5: kong.telemetry.v1.Entry.value:type_name -> kong.telemetry.v1.Value
+	8,  // 6: kong.telemetry.v1.Value.duration_value:type_name -> google.protobuf.Duration
+	7,  // 7: kong.telemetry.v1.Value.time_value:type_name -> google.protobuf.Timestamp
+	6,  // 8: kong.telemetry.v1.Value.string_list_value:type_name -> kong.telemetry.v1.StringList
+	3,  // 9: kong.telemetry.v1.Value.report_value:type_name -> kong.telemetry.v1.Report
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_pkg_telemetrypb_report_proto_init() }
+func file_pkg_telemetrypb_report_proto_init() {
+	if File_pkg_telemetrypb_report_proto != nil {
+		return
+	}
+	file_pkg_telemetrypb_report_proto_msgTypes[5].OneofWrappers = []any{
+		(*Value_StringValue)(nil),
+		(*Value_IntValue)(nil),
+		(*Value_FloatValue)(nil),
+		(*Value_BoolValue)(nil),
+		(*Value_DurationValue)(nil),
+		(*Value_TimeValue)(nil),
+		(*Value_StringListValue)(nil),
+		(*Value_ReportValue)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_telemetrypb_report_proto_rawDesc), len(file_pkg_telemetrypb_report_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pkg_telemetrypb_report_proto_goTypes,
+		DependencyIndexes: file_pkg_telemetrypb_report_proto_depIdxs,
+		MessageInfos:      file_pkg_telemetrypb_report_proto_msgTypes,
+	}.Build()
+	File_pkg_telemetrypb_report_proto = out.File
+	file_pkg_telemetrypb_report_proto_goTypes = nil
+	file_pkg_telemetrypb_report_proto_depIdxs = nil
+}
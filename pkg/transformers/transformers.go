@@ -0,0 +1,153 @@
+package transformers
+
+import (
+	"slices"
+
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+// keyAllowlist removes all keys which are not allowed.
+type keyAllowlist struct {
+	keys map[types.ProviderReportKey]struct{}
+}
+
+// NewKeyAllowlist creates a transformer which removes all keys that are not
+// listed from all workflows' reports. Nested reports are kept or removed as
+// a whole, depending on whether their key is allowed.
+func NewKeyAllowlist(keys ...types.ProviderReportKey) keyAllowlist {
+	m := make(map[types.ProviderReportKey]struct{}, len(keys))
+	for _, k := range keys {
+		m[k] = struct{}{}
+	}
+	return keyAllowlist{
+		keys: m,
+	}
+}
+
+// Transform removes keys which are not allowed.
+func (t keyAllowlist) Transform(sr types.SignalReport) (types.SignalReport, error) {
+	report := make(types.Report, len(sr.Report))
+	for name, pr := range sr.Report {
+		out := make(types.ProviderReport, len(pr))
+		for k, v := range pr {
+			if _, ok := t.keys[k]; ok {
+				out[k] = v
+			}
+		}
+		report[name] = out.Clone()
+	}
+	return types.SignalReport{Signal: sr.Signal, Report: report}, nil
+}
+
+// keyRenamer renames keys.
+type keyRenamer struct {
+	names map[types.ProviderReportKey]types.ProviderReportKey
+	// from holds the keys of names, sorted, so that renames are applied in a
+	// deterministic order.
+	from []types.ProviderReportKey
+}
+
+// NewKeyRenamer creates a transformer which renames keys in all workflows'
+// reports according to the provided mapping of old names to new names, e.g.
+// to keep a legacy backend working after a key has been renamed. Keys which
+// are not in the mapping are left intact.
+//
+// All keys are renamed at once, so renames are not chained: with a→b and b→c,
+// a's value ends up under b and b's value under c. A renamed value replaces the
+// value of a key which is already present under the new name. When several
+// keys present in a report are renamed to the same name, the value of the key
+// which sorts first is kept.
+func NewKeyRenamer(names map[types.ProviderReportKey]types.ProviderReportKey) keyRenamer {
+	from := make([]types.ProviderReportKey, 0, len(names))
+	for k := range names {
+		from = append(from, k)
+	}
+	slices.Sort(from)
+	return keyRenamer{
+		names: names,
+		from:  from,
+	}
+}
+
+// Transform renames keys.
+func (t keyRenamer) Transform(sr types.SignalReport) (types.SignalReport, error) {
+	report := sr.Report.Clone()
+	for name, pr := range report {
+		out := make(types.ProviderReport, len(pr))
+		for k, v := range pr {
+			if _, ok := t.names[k]; !ok {
+				out[k] = v
+			}
+		}
+		renamed := make(map[types.ProviderReportKey]struct{}, len(t.from))
+		for _, from := range t.from {
+			v, ok := pr[from]
+			if !ok {
+				continue
+			}
+			to := t.names[from]
+			if _, ok := renamed[to]; ok {
+				continue
+			}
+			out[to] = v
+			renamed[to] = struct{}{}
+		}
+		report[name] = out
+	}
+	return types.SignalReport{Signal: sr.Signal, Report: report}, nil
+}
+
+// staticLabels adds static labels to reports.
+type staticLabels struct {
+	labels types.ProviderReport
+}
+
+// NewStaticLabels creates a transformer which adds the provided labels to all
+// workflows' reports. Keys which are already present in a report are not
+// overridden.
+func NewStaticLabels(labels types.ProviderReport) staticLabels {
+	return staticLabels{
+		labels: labels,
+	}
+}
+
+// Transform adds static labels.
+func (t staticLabels) Transform(sr types.SignalReport) (types.SignalReport, error) {
+	report := sr.Report.Clone()
+	for _, pr := range report {
+		for k, v := range t.labels.Clone() {
+			if _, ok := pr[k]; !ok {
+				pr[k] = v
+			}
+		}
+	}
+	return types.SignalReport{Signal: sr.Signal, Report: report}, nil
+}
+
+// workflowDropper drops workflows' reports.
+type workflowDropper struct {
+	workflows map[string]struct{}
+}
+
+// NewWorkflowDropper creates a transformer which removes reports of workflows
+// with the provided names.
+func NewWorkflowDropper(workflows ...string) workflowDropper {
+	m := make(map[string]struct{}, len(workflows))
+	for _, w := range workflows {
+		m[w] = struct{}{}
+	}
+	return workflowDropper{
+		workflows: m,
+	}
+}
+
+// Transform removes reports of configured workflows.
+func (t workflowDropper) Transform(sr types.SignalReport) (types.SignalReport, error) {
+	report := make(types.Report, len(sr.Report))
+	for name, pr := range sr.Report {
+		if _, ok := t.workflows[name]; !ok {
+			report[name] = pr.Clone()
+		}
+	}
+	return types.SignalReport{Signal: sr.Signal, Report: report}, nil
+}
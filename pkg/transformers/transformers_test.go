@@ -0,0 +1,136 @@
+package transformers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/kong/kubernetes-telemetry/pkg/forwarders"
+	"github.com/kong/kubernetes-telemetry/pkg/telemetry"
+	"github.com/kong/kubernetes-telemetry/pkg/types"
+)
+
+func testReport() types.SignalReport {
+	return types.SignalReport{
+		Signal: "test-signal",
+		Report: types.Report{
+			"identify-platform": types.ProviderReport{
+				"k8sv":         "v1.27.1",
+				"k8s_provider": "GKE",
+			},
+			"state": types.ProviderReport{
+				"hn":     "host",
+				"uptime": 10,
+			},
+		},
+	}
+}
+
+func TestTransformers(t *testing.T) {
+	testcases := []struct {
+		name        string
+		transformer telemetry.Transformer
+		expected    types.Report
+	}{
+		{
+			name:        "key allowlist",
+			transformer: NewKeyAllowlist("k8sv", "uptime"),
+			expected: types.Report{
+				"identify-platform": types.ProviderReport{"k8sv": "v1.27.1"},
+				"state":             types.ProviderReport{"uptime": 10},
+			},
+		},
+		{
+			name:        "key renamer",
+			transformer: NewKeyRenamer(map[types.ProviderReportKey]types.ProviderReportKey{"k8sv": "k8s_version"}),
+			expected: types.Report{
+				"identify-platform": types.ProviderReport{"k8s_version": "v1.27.1", "k8s_provider": "GKE"},
+				"state":             types.ProviderReport{"hn": "host", "uptime": 10},
+			},
+		},
+		{
+			name: "key renamer doesn't chain renames",
+			transformer: NewKeyRenamer(map[types.ProviderReportKey]types.ProviderReportKey{
+				"k8sv":         "k8s_provider",
+				"k8s_provider": "provider",
+			}),
+			expected: types.Report{
+				"identify-platform": types.ProviderReport{"k8s_provider": "v1.27.1", "provider": "GKE"},
+				"state":             types.ProviderReport{"hn": "host", "uptime": 10},
+			},
+		},
+		{
+			name: "key renamer collisions",
+			transformer: NewKeyRenamer(map[types.ProviderReportKey]types.ProviderReportKey{
+				"uptime":       "hn",
+				"hn":           "version",
+				"k8sv":         "version",
+				"k8s_provider": "version",
+			}),
+			expected: types.Report{
+				"identify-platform": types.ProviderReport{"version": "GKE"},
+				"state":             types.ProviderReport{"version": "host", "hn": 10},
+			},
+		},
+		{
+			name:        "static labels",
+			transformer: NewStaticLabels(types.ProviderReport{"env": "prod", "hn": "other"}),
+			expected: types.Report{
+				"identify-platform": types.ProviderReport{"k8sv": "v1.27.1", "k8s_provider": "GKE", "env": "prod", "hn": "other"},
+				"state":             types.ProviderReport{"hn": "host", "uptime": 10, "env": "prod"},
+			},
+		},
+		{
+			name:        "workflow dropper",
+			transformer: NewWorkflowDropper("state"),
+			expected: types.Report{
+				"identify-platform": types.ProviderReport{"k8sv": "v1.27.1", "k8s_provider": "GKE"},
+			},
+		},
+		{
+			name: "chain",
+			transformer: telemetry.ChainTransformers(
+				NewWorkflowDropper("identify-platform"),
+				NewKeyRenamer(map[types.ProviderReportKey]types.ProviderReportKey{"hn": "hostname"}),
+				NewKeyAllowlist("hostname"),
+			),
+			expected: types.Report{
+				"state": types.ProviderReport{"hostname": "host"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := testReport()
+			out, err := tc.transformer.Transform(in)
+			require.NoError(t, err)
+			require.Equal(t, types.Signal("test-signal"), out.Signal)
+			require.Equal(t, tc.expected, out.Report)
+			require.Equal(t, testReport(), in, "input report must not be modified")
+		})
+	}
+}
+
+func TestConsumerWithTransformers(t *testing.T) {
+	ch := make(chan types.SignalReport)
+	c := telemetry.NewRawConsumer(
+		forwarders.NewRawChannelForwarder(ch),
+		telemetry.OptConsumerTransformers(
+			NewWorkflowDropper("identify-platform"),
+			NewKeyAllowlist("uptime"),
+		),
+	)
+	defer c.Close()
+
+	c.Intake() <- testReport()
+	select {
+	case sr := <-ch:
+		require.Equal(t, types.Report{
+			"state": types.ProviderReport{"uptime": 10},
+		}, sr.Report)
+	case <-time.After(time.Second):
+		require.Fail(t, "timed out waiting for report")
+	}
+}
@@ -21,6 +21,38 @@ func (r *ProviderReport) Merge(other ProviderReport) *ProviderReport {
 	return r
 }
 
+// Clone returns a deep copy of the report. Nested reports and string lists are
+// copied as well.
+func (r ProviderReport) Clone() ProviderReport {
+	if r == nil {
+		return nil
+	}
+	out := make(ProviderReport, len(r))
+	for k, v := range r {
+		switch vv := v.(type) {
+		case ProviderReport:
+			out[k] = vv.Clone()
+		case []string:
+			out[k] = append([]string(nil), vv...)
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Clone returns a deep copy of the report.
+func (r Report) Clone() Report {
+	if r == nil {
+		return nil
+	}
+	out := make(Report, len(r))
+	for name, pr := range r {
+		out[name] = pr.Clone()
+	}
+	return out
+}
+
 // Signal represents the signal name to include in the serialized report.
 type Signal string
 
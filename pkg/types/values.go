@@ -0,0 +1,216 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ValueType describes the type of a value stored under a ProviderReportKey.
+//
+// Reports can only contain values of the types listed below. Named types are
+// supported as long as their underlying type is supported, e.g. a named string
+// type is reported as ValueTypeString.
+type ValueType string
+
+const (
+	// ValueTypeString represents string values.
+	ValueTypeString = ValueType("string")
+	// ValueTypeInt represents integer values which fit in an int64.
+	ValueTypeInt = ValueType("int")
+	// ValueTypeFloat represents finite floating point values.
+	ValueTypeFloat = ValueType("float")
+	// ValueTypeBool represents boolean values.
+	ValueTypeBool = ValueType("bool")
+	// ValueTypeDuration represents time.Duration values.
+	ValueTypeDuration = ValueType("duration")
+	// ValueTypeTime represents time.Time values.
+	ValueTypeTime = ValueType("time")
+	// ValueTypeStringList represents lists of strings.
+	ValueTypeStringList = ValueType("stringlist")
+	// ValueTypeReport represents nested ProviderReports.
+	ValueTypeReport = ValueType("report")
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	reportType   = reflect.TypeOf(ProviderReport{})
+)
+
+// ErrUnsupportedValue is an error which indicates that a report contains a
+// value which type is not supported.
+type ErrUnsupportedValue struct {
+	// Key is the key under which the value was found. For nested reports it
+	// contains keys of all enclosing reports joined with a dot. It's empty when
+	// the value was checked outside of a report.
+	Key ProviderReportKey
+	// Value is the offending value.
+	Value any
+	// Reason describes why the value is not supported.
+	Reason string
+}
+
+func (e ErrUnsupportedValue) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("unsupported value %v of type %T: %s", e.Value, e.Value, e.Reason)
+	}
+	return fmt.Sprintf("unsupported value %v of type %T under key %q: %s", e.Value, e.Value, e.Key, e.Reason)
+}
+
+// ValueTypeOf returns the ValueType of the provided value. It returns an
+// ErrUnsupportedValue when the value's type is not supported. Values of nested
+// reports are not checked, use ProviderReport's Validate for that.
+func ValueTypeOf(v any) (ValueType, error) {
+	t, reason := valueTypeOf(v)
+	if reason != "" {
+		return "", ErrUnsupportedValue{Value: v, Reason: reason}
+	}
+	return t, nil
+}
+
+func valueTypeOf(v any) (ValueType, string) {
+	if v == nil {
+		return "", "nil values are not allowed"
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rt := rv.Type(); {
+	case rt == durationType:
+		return ValueTypeDuration, ""
+	case rt == timeType:
+		return ValueTypeTime, ""
+	case rt == reportType:
+		return ValueTypeReport, ""
+	}
+
+	switch rv.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return ValueTypeString, ""
+	case reflect.Bool:
+		return ValueTypeBool, ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ValueTypeInt, ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return "", "integer overflows int64"
+		}
+		return ValueTypeInt, ""
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", "float is not finite"
+		}
+		return ValueTypeFloat, ""
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.String {
+			return ValueTypeStringList, ""
+		}
+	}
+
+	return "", "type is not supported"
+}
+
+// CanonicalValue converts the provided value into the canonical Go type of its
+// ValueType: string, int64, float64, bool, time.Duration, time.Time, []string
+// or ProviderReport. This allows serializers to handle named types, like
+// provider.ClusterProvider, without resorting to reflection.
+func CanonicalValue(v any) (any, ValueType, error) {
+	t, err := ValueTypeOf(v)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rv := reflect.ValueOf(v)
+	switch t {
+	case ValueTypeString:
+		return rv.String(), t, nil
+	case ValueTypeInt:
+		if rv.CanInt() {
+			return rv.Int(), t, nil
+		}
+		return int64(rv.Uint()), t, nil //nolint:gosec
+	case ValueTypeFloat:
+		return rv.Float(), t, nil
+	case ValueTypeBool:
+		return rv.Bool(), t, nil
+	case ValueTypeStringList:
+		if l, ok := v.([]string); ok {
+			return l, t, nil
+		}
+		l := make([]string, rv.Len())
+		for i := range l {
+			l[i] = rv.Index(i).String()
+		}
+		return l, t, nil
+	case ValueTypeDuration, ValueTypeTime, ValueTypeReport:
+		return v, t, nil
+	}
+	return v, t, nil
+}
+
+// Validate checks that all values in the report, including values in nested
+// reports, are of supported types. It returns an error wrapping
+// ErrUnsupportedValue for every unsupported value.
+func (r ProviderReport) Validate() error {
+	return r.validate("")
+}
+
+func (r ProviderReport) validate(prefix ProviderReportKey) error {
+	var errs []error
+	for _, k := range r.Keys() {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		t, reason := valueTypeOf(r[k])
+		if reason != "" {
+			errs = append(errs, ErrUnsupportedValue{Key: key, Value: r[k], Reason: reason})
+			continue
+		}
+		if t == ValueTypeReport {
+			if err := r[k].(ProviderReport).validate(key); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Keys returns the report's keys in sorted order.
+func (r ProviderReport) Keys() []ProviderReportKey {
+	keys := make([]ProviderReportKey, 0, len(r))
+	for k := range r {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// Validate checks that all workflows' reports contain only values of supported
+// types.
+func (r Report) Validate() error {
+	var errs []error
+	for _, name := range r.Names() {
+		if err := r[name].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("workflow %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Names returns the names of workflows which reports are included in the report,
+// in sorted order.
+func (r Report) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type namedString string
+
+func TestValueTypeOf(t *testing.T) {
+	testcases := []struct {
+		name     string
+		value    any
+		expected ValueType
+		wantErr  bool
+	}{
+		{name: "string", value: "abc", expected: ValueTypeString},
+		{name: "named string", value: namedString("abc"), expected: ValueTypeString},
+		{name: "int", value: 1, expected: ValueTypeInt},
+		{name: "uint64", value: uint64(1), expected: ValueTypeInt},
+		{name: "overflowing uint64", value: uint64(math.MaxUint64), wantErr: true},
+		{name: "float", value: 1.5, expected: ValueTypeFloat},
+		{name: "NaN", value: math.NaN(), wantErr: true},
+		{name: "bool", value: true, expected: ValueTypeBool},
+		{name: "duration", value: time.Second, expected: ValueTypeDuration},
+		{name: "time", value: time.Now(), expected: ValueTypeTime},
+		{name: "string list", value: []string{"a"}, expected: ValueTypeStringList},
+		{name: "named string list", value: []namedString{"a"}, expected: ValueTypeStringList},
+		{name: "report", value: ProviderReport{}, expected: ValueTypeReport},
+		{name: "nil", value: nil, wantErr: true},
+		{name: "struct", value: struct{}{}, wantErr: true},
+		{name: "pointer", value: new(int), wantErr: true},
+		{name: "channel", value: make(chan int), wantErr: true},
+		{name: "int list", value: []int{1}, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			vt, err := ValueTypeOf(tc.value)
+			if tc.wantErr {
+				require.ErrorAs(t, err, &ErrUnsupportedValue{})
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, vt)
+		})
+	}
+}
+
+func TestCanonicalValue(t *testing.T) {
+	v, vt, err := CanonicalValue(namedString("abc"))
+	require.NoError(t, err)
+	require.Equal(t, ValueTypeString, vt)
+	require.Equal(t, "abc", v)
+
+	v, _, err = CanonicalValue(uint8(3))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), v)
+
+	v, _, err = CanonicalValue([]namedString{"a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, v)
+}
+
+func TestReportValidate(t *testing.T) {
+	require.NoError(t, Report{
+		"w": ProviderReport{
+			"a": "b",
+			"n": ProviderReport{
+				"c": 1,
+			},
+		},
+	}.Validate())
+
+	err := Report{
+		"w": ProviderReport{
+			"a": "b",
+			"n": ProviderReport{
+				"c": struct{}{},
+			},
+		},
+	}.Validate()
+	require.Error(t, err)
+	var errUnsupported ErrUnsupportedValue
+	require.ErrorAs(t, err, &errUnsupported)
+	require.Equal(t, ProviderReportKey("n.c"), errUnsupported.Key)
+	require.Contains(t, err.Error(), "workflow w")
+}
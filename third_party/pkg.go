@@ -3,4 +3,5 @@ package third_party
 import (
 	_ "github.com/golangci/golangci-lint/cmd/golangci-lint"
 	_ "github.com/gotesttools/gotestfmt/v2/cmd/gotestfmt"
+	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
 )